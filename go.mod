@@ -3,7 +3,6 @@ module github.com/fiorix/go-smpp/v2
 go 1.15
 
 require (
-	github.com/fiorix/go-smpp v0.0.0-20210403173735-2894b96e70ba
 	github.com/urfave/cli v1.22.5
 	golang.org/x/text v0.3.6
 	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
@@ -0,0 +1,81 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+)
+
+// MSAvailability is the ms_availability_status of an alert_notification.
+type MSAvailability uint8
+
+// Supported MS availability statuses.
+const (
+	MSAvailable   MSAvailability = 0x00
+	MSDenied      MSAvailability = 0x01 // e.g. suspended, no SMS capability.
+	MSUnavailable MSAvailability = 0x02
+)
+
+var msAvailabilityText = map[MSAvailability]string{
+	MSAvailable:   "Available",
+	MSDenied:      "Denied",
+	MSUnavailable: "Unavailable",
+}
+
+// String implements the Stringer interface.
+func (s MSAvailability) String() string {
+	return msAvailabilityText[s]
+}
+
+// Alert contains the data of an alert_notification PDU, sent by the
+// SMSC when a subscriber with pending messages becomes reachable.
+type Alert struct {
+	Src          string // Address of the subscriber.
+	SrcTON       uint8
+	SrcNPI       uint8
+	EsmeAddr     string // Address of the ESME that requested the alert.
+	EsmeAddrTON  uint8
+	EsmeAddrNPI  uint8
+	Availability MSAvailability // MSAvailable if not present in the PDU.
+}
+
+// AlertHandlerFunc is the handler function that a Receiver or
+// Transceiver calls when an alert_notification PDU arrives.
+type AlertHandlerFunc func(a *Alert)
+
+// newAlert returns a new Alert built from an alert_notification PDU.
+func newAlert(p pdu.Body) *Alert {
+	f := p.Fields()
+	a := &Alert{
+		Src:         fieldString(f, pdufield.SourceAddr),
+		SrcTON:      fieldUint8(f, pdufield.SourceAddrTON),
+		SrcNPI:      fieldUint8(f, pdufield.SourceAddrNPI),
+		EsmeAddr:    fieldString(f, pdufield.EsmeAddr),
+		EsmeAddrTON: fieldUint8(f, pdufield.EsmeAddrTON),
+		EsmeAddrNPI: fieldUint8(f, pdufield.EsmeAddrNPI),
+	}
+	if v, ok := p.TLVFields().Uint8(pdutlv.TagMsAvailabilityStatus); ok {
+		a.Availability = MSAvailability(v)
+	}
+	return a
+}
+
+// fieldString returns the string value of field n, or empty.
+func fieldString(f pdufield.Map, n pdufield.Name) string {
+	if v := f[n]; v != nil {
+		return v.String()
+	}
+	return ""
+}
+
+// fieldUint8 returns the value of the fixed field n, or zero.
+func fieldUint8(f pdufield.Map, n pdufield.Name) uint8 {
+	if v, ok := f[n].(*pdufield.Fixed); ok {
+		return v.Data
+	}
+	return 0
+}
@@ -0,0 +1,239 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+)
+
+// Network types of the broadcast_content_type TLV.
+const (
+	BroadcastGeneric = 0x00
+	BroadcastGSM     = 0x01
+	BroadcastTDMA    = 0x02
+	BroadcastCDMA    = 0x03
+)
+
+// BroadcastMessage configures a cell broadcast message, available
+// when the interface version negotiated with the SMSC is V50.
+type BroadcastMessage struct {
+	Src           string
+	SourceAddrTON uint8
+	SourceAddrNPI uint8
+	ServiceType   string
+
+	// Text of the message, sent in the message_payload TLV.
+	Text pdutext.Codec
+
+	// Area is the broadcast_area_identifier: the area format
+	// followed by the area details, as agreed with the SMSC.
+	Area []byte
+
+	// NetworkType, e.g. BroadcastGSM, and ContentType of the
+	// broadcast_content_type TLV.
+	NetworkType uint8
+	ContentType uint16
+
+	// RepNum is the number of times the message is broadcast, and
+	// Frequency the interval between repetitions, or 0 to repeat as
+	// frequently as possible.
+	RepNum    uint16
+	Frequency time.Duration
+
+	// Validity period of the message, optional.
+	Validity time.Duration
+
+	PriorityFlag         uint8
+	ScheduleDeliveryTime string
+
+	// Other TLVs, optional.
+	TLVFields pdutlv.Fields
+}
+
+// QueryBroadcastResp contains the parsed query_broadcast_sm_resp.
+type QueryBroadcastResp struct {
+	MsgID       string
+	MsgState    string
+	Area        []byte // broadcast_area_identifier
+	AreaSuccess uint8  // Percentage of the area reached, 255 if unknown.
+	EndTime     string // broadcast_end_time, if any.
+}
+
+// checkV50 returns ErrNotSupported if the interface version negotiated
+// on the current connection is lower than V50.
+func (t *Transmitter) checkV50() error {
+	if v := t.NegotiatedVersion(); v != 0 && v < V50 {
+		return ErrNotSupported
+	}
+	return nil
+}
+
+// SubmitBroadcast sends a cell broadcast message and returns its
+// message id.
+func (t *Transmitter) SubmitBroadcast(bm *BroadcastMessage) (string, error) {
+	return t.SubmitBroadcastContext(context.Background(), bm)
+}
+
+// SubmitBroadcastContext is like SubmitBroadcast but honours the
+// deadline and cancellation of ctx, returning ctx.Err() when ctx is
+// done first.
+func (t *Transmitter) SubmitBroadcastContext(ctx context.Context, bm *BroadcastMessage) (string, error) {
+	if err := t.checkV50(); err != nil {
+		return "", err
+	}
+	p := pdu.NewBroadcastSM(bm.TLVFields)
+	f := p.Fields()
+	f.Set(pdufield.ServiceType, bm.ServiceType)
+	f.Set(pdufield.SourceAddrTON, bm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, bm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, bm.Src)
+	f.Set(pdufield.PriorityFlag, bm.PriorityFlag)
+	f.Set(pdufield.ScheduleDeliveryTime, bm.ScheduleDeliveryTime)
+	if bm.Validity != time.Duration(0) {
+		f.Set(pdufield.ValidityPeriod, convertValidity(bm.Validity))
+	}
+	tlv := p.TLVFields()
+	if bm.Text != nil {
+		f.Set(pdufield.DataCoding, uint8(bm.Text.Type()))
+		tlv.Set(pdutlv.TagMessagePayload, bm.Text.Encode())
+	}
+	ct := []byte{bm.NetworkType, uint8(bm.ContentType >> 8), uint8(bm.ContentType)}
+	tlv.Set(pdutlv.TagBroadcastAreaIdentifier, pdutlv.NewOctets(pdutlv.TagBroadcastAreaIdentifier, bm.Area))
+	tlv.Set(pdutlv.TagBroadcastContentType, pdutlv.NewOctets(pdutlv.TagBroadcastContentType, ct))
+	tlv.Set(pdutlv.TagBroadcastRepNum, pdutlv.NewUint16(pdutlv.TagBroadcastRepNum, bm.RepNum))
+	tlv.Set(pdutlv.TagBroadcastFrequencyInterval, pdutlv.NewOctets(pdutlv.TagBroadcastFrequencyInterval,
+		frequencyInterval(bm.Frequency)))
+	resp, err := t.do(ctx, p)
+	if err != nil {
+		return "", err
+	}
+	if id := resp.PDU.Header().ID; id != pdu.BroadcastSMRespID {
+		return "", fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return "", s
+	}
+	return resp.PDU.Fields()[pdufield.MessageID].String(), nil
+}
+
+// QueryBroadcast queries the state of a previously submitted cell
+// broadcast message.
+func (t *Transmitter) QueryBroadcast(src, msgid string, srcTON, srcNPI uint8) (*QueryBroadcastResp, error) {
+	return t.QueryBroadcastContext(context.Background(), src, msgid, srcTON, srcNPI)
+}
+
+// QueryBroadcastContext is like QueryBroadcast but honours the
+// deadline and cancellation of ctx, returning ctx.Err() when ctx is
+// done first.
+func (t *Transmitter) QueryBroadcastContext(ctx context.Context, src, msgid string, srcTON, srcNPI uint8) (*QueryBroadcastResp, error) {
+	if err := t.checkV50(); err != nil {
+		return nil, err
+	}
+	p := pdu.NewQueryBroadcastSM()
+	f := p.Fields()
+	f.Set(pdufield.MessageID, msgid)
+	f.Set(pdufield.SourceAddrTON, srcTON)
+	f.Set(pdufield.SourceAddrNPI, srcNPI)
+	f.Set(pdufield.SourceAddr, src)
+	resp, err := t.do(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if id := resp.PDU.Header().ID; id != pdu.QueryBroadcastSMRespID {
+		return nil, fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return nil, s
+	}
+	tlv := resp.PDU.TLVFields()
+	ms, ok := tlv.Uint8(pdutlv.TagMessageState)
+	if !ok {
+		return nil, fmt.Errorf("no state available")
+	}
+	qr := &QueryBroadcastResp{MsgID: msgid, MsgState: messageState(ms), AreaSuccess: 255}
+	qr.Area, _ = tlv.Octets(pdutlv.TagBroadcastAreaIdentifier)
+	if v, ok := tlv.Uint8(pdutlv.TagBroadcastAreaSuccess); ok {
+		qr.AreaSuccess = v
+	}
+	qr.EndTime, _ = tlv.CString(pdutlv.TagBroadcastEndTime)
+	return qr, nil
+}
+
+// CancelBroadcast cancels a previously submitted cell broadcast
+// message, using the source address and ServiceType of bm.
+func (t *Transmitter) CancelBroadcast(msgid string, bm *BroadcastMessage) error {
+	return t.CancelBroadcastContext(context.Background(), msgid, bm)
+}
+
+// CancelBroadcastContext is like CancelBroadcast but honours the
+// deadline and cancellation of ctx, returning ctx.Err() when ctx is
+// done first.
+func (t *Transmitter) CancelBroadcastContext(ctx context.Context, msgid string, bm *BroadcastMessage) error {
+	if err := t.checkV50(); err != nil {
+		return err
+	}
+	p := pdu.NewCancelBroadcastSM()
+	f := p.Fields()
+	f.Set(pdufield.ServiceType, bm.ServiceType)
+	f.Set(pdufield.MessageID, msgid)
+	f.Set(pdufield.SourceAddrTON, bm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, bm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, bm.Src)
+	resp, err := t.do(ctx, p)
+	if err != nil {
+		return err
+	}
+	if id := resp.PDU.Header().ID; id != pdu.CancelBroadcastSMRespID {
+		return fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return s
+	}
+	return nil
+}
+
+// frequencyInterval returns the broadcast_frequency_interval of d: a
+// unit of time followed by a 2 octet number of units. The largest unit
+// that represents d exactly is used, otherwise d is rounded.
+func frequencyInterval(d time.Duration) []byte {
+	units := []struct {
+		id byte
+		d  time.Duration
+	}{
+		{0x0A, time.Hour},
+		{0x09, time.Minute},
+		{0x08, time.Second},
+	}
+	b := make([]byte, 3)
+	if d <= 0 {
+		return b // as frequently as possible.
+	}
+	for _, u := range units {
+		if d%u.d == 0 && d/u.d <= 0xFFFF {
+			b[0] = u.id
+			binary.BigEndian.PutUint16(b[1:], uint16(d/u.d))
+			return b
+		}
+	}
+	for i := len(units) - 1; i >= 0; i-- {
+		u := units[i]
+		if n := (d + u.d/2) / u.d; n <= 0xFFFF {
+			b[0] = u.id
+			binary.BigEndian.PutUint16(b[1:], uint16(n))
+			return b
+		}
+	}
+	b[0] = units[0].id
+	binary.BigEndian.PutUint16(b[1:], 0xFFFF)
+	return b
+}
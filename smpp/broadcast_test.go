@@ -0,0 +1,164 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+)
+
+func TestNegotiatedVersion(t *testing.T) {
+	test := []struct {
+		requested, server, want uint8
+	}{
+		{0, 0, V34},
+		{V50, 0, V34},
+		{V50, V34, V34},
+		{V50, V50, V50},
+		{V34, V50, V34},
+		{V33, V50, V33},
+	}
+	for _, tc := range test {
+		s := smpptest.NewUnstartedServer()
+		s.InterfaceVersion = tc.server
+		s.Start()
+		tx := &Transmitter{
+			Addr:             s.Addr(),
+			User:             smpptest.DefaultUser,
+			Passwd:           smpptest.DefaultPasswd,
+			InterfaceVersion: tc.requested,
+		}
+		conn := <-tx.Bind()
+		if conn.Status() != Connected {
+			t.Fatal(conn.Error())
+		}
+		if v := tx.NegotiatedVersion(); v != tc.want {
+			t.Fatalf("requested %#x, server %#x: unexpected version: want %#x, have %#x",
+				tc.requested, tc.server, tc.want, v)
+		}
+		tx.Close()
+		s.Close()
+	}
+}
+
+func TestBroadcastNotSupported(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:             s.Addr(),
+		User:             smpptest.DefaultUser,
+		Passwd:           smpptest.DefaultPasswd,
+		InterfaceVersion: V50,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	if _, err := tx.SubmitBroadcast(&BroadcastMessage{}); err != ErrNotSupported {
+		t.Fatalf("unexpected error: want ErrNotSupported, have %v", err)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.InterfaceVersion = V50
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		var r pdu.Body
+		switch p.Header().ID {
+		case pdu.BroadcastSMID:
+			r = pdu.NewBroadcastSMRespSeq(p.Header().Seq)
+			tlv := p.TLVFields()
+			ri, _ := tlv.Uint16(pdutlv.TagBroadcastRepNum)
+			fi, _ := tlv.Octets(pdutlv.TagBroadcastFrequencyInterval)
+			ct, _ := tlv.Octets(pdutlv.TagBroadcastContentType)
+			area, _ := tlv.Octets(pdutlv.TagBroadcastAreaIdentifier)
+			text, _ := tlv.Octets(pdutlv.TagMessagePayload)
+			if ri != 3 || !bytes.Equal(fi, []byte{0x09, 0x00, 0x05}) ||
+				!bytes.Equal(ct, []byte{BroadcastGSM, 0x00, 0x01}) ||
+				!bytes.Equal(area, []byte{0x00, 0x12, 0x34}) || string(text) != "alert" {
+				r.Header().Status = 0xC4 // invalid TLV value
+				break
+			}
+			r.Fields().Set(pdufield.MessageID, "b1")
+		case pdu.QueryBroadcastSMID:
+			r = pdu.NewQueryBroadcastSMRespSeq(p.Header().Seq)
+			r.Fields().Set(pdufield.MessageID, p.Fields()[pdufield.MessageID])
+			r.TLVFields().Set(pdutlv.TagMessageState, uint8(1))
+			r.TLVFields().Set(pdutlv.TagBroadcastAreaIdentifier, []byte{0x00, 0x12, 0x34})
+			r.TLVFields().Set(pdutlv.TagBroadcastAreaSuccess, uint8(80))
+		case pdu.CancelBroadcastSMID:
+			r = pdu.NewCancelBroadcastSMRespSeq(p.Header().Seq)
+		default:
+			return
+		}
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:             s.Addr(),
+		User:             smpptest.DefaultUser,
+		Passwd:           smpptest.DefaultPasswd,
+		InterfaceVersion: V50,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	bm := &BroadcastMessage{
+		Src:         "root",
+		Text:        pdutext.Raw("alert"),
+		Area:        []byte{0x00, 0x12, 0x34},
+		NetworkType: BroadcastGSM,
+		ContentType: 0x0001,
+		RepNum:      3,
+		Frequency:   5 * time.Minute,
+	}
+	msgid, err := tx.SubmitBroadcast(bm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgid != "b1" {
+		t.Fatalf("unexpected message id: %q", msgid)
+	}
+	qr, err := tx.QueryBroadcast("root", msgid, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qr.MsgState != "ENROUTE" || qr.AreaSuccess != 80 || !bytes.Equal(qr.Area, bm.Area) {
+		t.Fatalf("unexpected query response: %#v", qr)
+	}
+	if err := tx.CancelBroadcast(msgid, bm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFrequencyInterval(t *testing.T) {
+	test := []struct {
+		d    time.Duration
+		want []byte
+	}{
+		{0, []byte{0x00, 0x00, 0x00}},
+		{30 * time.Second, []byte{0x08, 0x00, 0x1E}},
+		{90 * time.Second, []byte{0x08, 0x00, 0x5A}},
+		{2 * time.Minute, []byte{0x09, 0x00, 0x02}},
+		{3 * time.Hour, []byte{0x0A, 0x00, 0x03}},
+		{1500 * time.Millisecond, []byte{0x08, 0x00, 0x02}},
+	}
+	for _, tc := range test {
+		if have := frequencyInterval(tc.d); !bytes.Equal(have, tc.want) {
+			t.Fatalf("%s: unexpected interval: want %x, have %x", tc.d, tc.want, have)
+		}
+	}
+}
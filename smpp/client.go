@@ -10,10 +10,20 @@ import (
 	"io"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+)
+
+// SMPP interface versions, for the interface_version field of bind
+// PDUs and the sc_interface_version TLV of their responses.
+const (
+	V33 uint8 = 0x33
+	V34 uint8 = 0x34
+	V50 uint8 = 0x50
 )
 
 // ConnStatus is an abstract interface for a connection status change.
@@ -30,6 +40,29 @@ type connStatus struct {
 func (c *connStatus) Status() ConnStatusID { return c.s }
 func (c *connStatus) Error() error         { return c.err }
 
+// ReconnectStatus is the ConnStatus of a client waiting to reconnect,
+// after the connection failed or was lost.
+type ReconnectStatus struct {
+	Attempt int           // Number of the next attempt since the last bind, from 1.
+	Delay   time.Duration // Time until the next attempt.
+}
+
+// Status implements the ConnStatus interface.
+func (r *ReconnectStatus) Status() ConnStatusID { return Reconnecting }
+
+// Error implements the ConnStatus interface.
+func (r *ReconnectStatus) Error() error { return nil }
+
+// ConnStatusHandlerFunc is the handler function that clients call on
+// every connection status change.
+//
+// It is called synchronously, in order, from the goroutine that
+// manages the connection, which waits for it to return before it
+// reconnects, reads or sends enquire_link. It must not block, e.g. on
+// a channel that is not drained, nor call the client's Close, Unbind
+// or Shutdown; hand the status off to another goroutine instead.
+type ConnStatusHandlerFunc func(s ConnStatus)
+
 // ConnStatusID represents a connection status change.
 type ConnStatusID uint8
 
@@ -39,13 +72,19 @@ const (
 	Disconnected
 	ConnectionFailed
 	BindFailed
+	Unbound            // The SMSC sent unbind.
+	EnquireLinkTimeout // The SMSC did not answer enquire_link in time.
+	Reconnecting       // Waiting to reconnect, see ReconnectStatus.
 )
 
 var connStatusText = map[ConnStatusID]string{
-	Connected:        "Connected",
-	Disconnected:     "Disconnected",
-	ConnectionFailed: "Connection failed",
-	BindFailed:       "Bind failed",
+	Connected:          "Connected",
+	Disconnected:       "Disconnected",
+	ConnectionFailed:   "Connection failed",
+	BindFailed:         "Bind failed",
+	Unbound:            "Unbound",
+	EnquireLinkTimeout: "Enquire link timeout",
+	Reconnecting:       "Reconnecting",
 }
 
 // String implements the Stringer interface.
@@ -58,12 +97,14 @@ func (cs ConnStatusID) String() string {
 type ClientConn interface {
 	// Bind starts the client connection and returns a
 	// channel that is triggered every time the connection
-	// status changes.
+	// status changes. If the channel is not drained, it
+	// holds the latest status only; use ConnStatusHandler
+	// to get every change.
 	Bind() <-chan ConnStatus
 
 	// Closer embeds the Closer interface. When Close is
 	// called, client sends the Unbind command first and
-	// terminates the connection upon response, or 1s timeout.
+	// terminates the connection upon UnbindResp, or 1s timeout.
 	Closer
 }
 
@@ -72,7 +113,8 @@ type ClientConn interface {
 //
 // The Transmitter or Transceiver using the RateLimiter holds a
 // single context.Context per client connection, passed to Wait
-// prior to sending short messages.
+// prior to sending short messages. Methods such as SubmitContext
+// pass the caller's context instead.
 //
 // Suitable for use with package golang.org/x/time/rate.
 type RateLimiter interface {
@@ -85,28 +127,52 @@ type client struct {
 	Addr               string
 	TLS                *tls.Config
 	Status             chan ConnStatus
+	StatusHandler      ConnStatusHandlerFunc
 	BindFunc           func(c Conn) error
+	DialFunc           func() (Conn, error) // Dials Addr if nil.
 	EnquireLink        time.Duration
 	EnquireLinkTimeout time.Duration
 	RespTimeout        time.Duration
 	BindInterval       time.Duration
 	WindowSize         uint
+	WindowWait         bool
 	RateLimiter        RateLimiter
+	FlowControl        *FlowControl
+	RetryPolicy        RetryPolicy
+	Metrics            Metrics
+	WrapConn           func(Conn) Conn
 
 	// internal stuff.
-	inbox chan pdu.Body
-	conn  *connSwitch
-	stop  chan struct{}
-	once  sync.Once
-	lmctx context.Context
+	window *window
+	flow   *flow
+	inbox  chan pdu.Body // replaced on every connection, guarded by inmu.
+	inmu   sync.Mutex
+	conn   *connSwitch
+	stop   chan struct{}
+	once   sync.Once
+	lmctx  context.Context
+	state  uint32 // last ConnStatusID, atomic.
+	// sequence number of the unbind sent by shutdown, atomic, and
+	// the channel closed when its response arrives.
+	unbindSeq uint32
+	unbound   chan struct{}
+	ubOnce    sync.Once
 	// time of the last received EnquireLinkResp
 	eliTime time.Time
 	eliMtx  sync.RWMutex
 }
 
 func (c *client) init() {
-	c.conn = &connSwitch{}
+	c.conn = &connSwitch{metrics: c.Metrics}
 	c.stop = make(chan struct{})
+	c.unbound = make(chan struct{})
+	c.window = newWindow(c.WindowSize, c.WindowWait)
+	if c.FlowControl != nil {
+		c.flow = newFlow(c.FlowControl, c.window, c.RateLimiter)
+		if c.flow.lim == nil && c.flow.cfg.Rate > 0 {
+			c.RateLimiter = nil // replaced by the flow control.
+		}
+	}
 	if c.RateLimiter != nil {
 		c.lmctx = context.Background()
 	}
@@ -124,10 +190,15 @@ func (c *client) init() {
 func (c *client) Bind() {
 	delay := 1.0
 	const maxdelay = 120.0
+	attempts := 0
 	for !c.closed() {
 		eli := make(chan struct{})
-		c.inbox = make(chan pdu.Body)
-		conn, err := Dial(c.Addr, c.TLS)
+		elt := make(chan struct{}) // closed on enquire link timeout.
+		inbox := make(chan pdu.Body)
+		c.inmu.Lock()
+		c.inbox = inbox
+		c.inmu.Unlock()
+		conn, err := c.dial()
 		if err != nil {
 			c.notify(&connStatus{
 				s:   ConnectionFailed,
@@ -135,24 +206,41 @@ func (c *client) Bind() {
 			})
 			goto retry
 		}
+		if c.WrapConn != nil {
+			conn = c.WrapConn(conn)
+		}
 		c.conn.Set(conn)
 		if err = c.BindFunc(c.conn); err != nil {
 			c.notify(&connStatus{s: BindFailed, err: err})
 			goto retry
 		}
-		go c.enquireLink(eli)
+		go c.enquireLink(eli, elt)
 		c.notify(&connStatus{s: Connected})
 		delay = 1
+		attempts = 0
 		for {
 			p, err := c.conn.Read()
 			if err != nil {
-				c.notify(&connStatus{
-					s:   Disconnected,
-					err: err,
-				})
+				s := Disconnected
+				select {
+				case <-elt:
+					s = EnquireLinkTimeout
+				default:
+				}
+				c.notify(&connStatus{s: s, err: err})
 				break
 			}
 			switch p.Header().ID {
+			case pdu.UnbindID:
+				c.conn.Write(pdu.NewUnbindRespSeq(p.Header().Seq))
+				if !c.closed() {
+					c.notify(&connStatus{s: Unbound})
+					goto retry
+				}
+			case pdu.UnbindRespID:
+				if p.Header().Seq == atomic.LoadUint32(&c.unbindSeq) {
+					c.ubOnce.Do(func() { close(c.unbound) })
+				}
 			case pdu.EnquireLinkID:
 				pResp := pdu.NewEnquireLinkRespSeq(p.Header().Seq)
 				err := c.conn.Write(pResp)
@@ -162,24 +250,41 @@ func (c *client) Bind() {
 			case pdu.EnquireLinkRespID:
 				c.updateEliTime()
 			default:
-				c.inbox <- p
+				select {
+				case inbox <- p:
+				case <-c.stop:
+				}
 			}
 		}
 	retry:
 		close(eli)
 		c.conn.Close()
-		close(c.inbox)
+		close(inbox)
 		delayDuration := c.BindInterval
 		if delayDuration == 0 {
 			delay = math.Min(delay*math.E, maxdelay)
 			delayDuration = time.Duration(delay) * time.Second
 		}
+		if !c.closed() {
+			attempts++
+			c.notify(&ReconnectStatus{Attempt: attempts, Delay: delayDuration})
+		}
 		c.trysleep(delayDuration)
 	}
 	close(c.Status)
 }
 
-func (c *client) enquireLink(stop chan struct{}) {
+// dial returns a new connection using DialFunc, or Dial when not set.
+func (c *client) dial() (Conn, error) {
+	if c.DialFunc != nil {
+		return c.DialFunc()
+	}
+	return Dial(c.Addr, c.TLS)
+}
+
+// enquireLink sends enquire_link periodically until stop is closed,
+// or closes the connection and timeout if the SMSC stops answering.
+func (c *client) enquireLink(stop, timeout chan struct{}) {
 	// for the first check set time as Now()
 	c.updateEliTime()
 	for {
@@ -188,6 +293,7 @@ func (c *client) enquireLink(stop chan struct{}) {
 			// check the time of the last received EnquireLinkResp
 			c.eliMtx.RLock()
 			if time.Since(c.eliTime) >= c.EnquireLinkTimeout {
+				close(timeout)
 				c.conn.Write(pdu.NewUnbind())
 				c.conn.Close()
 				c.eliMtx.RUnlock()
@@ -213,17 +319,46 @@ func (c *client) updateEliTime() {
 	c.eliMtx.Unlock()
 }
 
+// notify reports the status change ev to the StatusHandler, if any,
+// and on the Status channel. The channel keeps the latest status when
+// it is not drained, replacing older ones.
 func (c *client) notify(ev ConnStatus) {
-	select {
-	case c.Status <- ev:
-	default:
+	atomic.StoreUint32(&c.state, uint32(ev.Status()))
+	if c.Metrics != nil {
+		c.Metrics.ConnStatus(ev.Status())
+	}
+	if c.StatusHandler != nil {
+		c.StatusHandler(ev)
+	}
+	for {
+		select {
+		case c.Status <- ev:
+			return
+		default:
+		}
+		select {
+		case <-c.Status:
+		default:
+		}
 	}
 }
 
+// State returns the last status of the connection.
+func (c *client) State() ConnStatusID {
+	return ConnStatusID(atomic.LoadUint32(&c.state))
+}
+
+// getInbox returns the inbox of the current connection.
+func (c *client) getInbox() chan pdu.Body {
+	c.inmu.Lock()
+	defer c.inmu.Unlock()
+	return c.inbox
+}
+
 // Read reads PDU binary data off the wire and returns it.
 func (c *client) Read() (pdu.Body, error) {
 	select {
-	case pdu := <-c.inbox:
+	case pdu := <-c.getInbox():
 		return pdu, nil
 	case <-c.stop:
 		return nil, io.EOF
@@ -238,19 +373,62 @@ func (c *client) Write(w pdu.Body) error {
 	return c.conn.Write(w)
 }
 
+// WriteContext is like Write but waits for the RateLimiter using the
+// given ctx, and returns an error if ctx is done before the limiter
+// permits the PDU to be sent.
+func (c *client) WriteContext(ctx context.Context, w pdu.Body) error {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+	}
+	return c.conn.WriteContext(ctx, w)
+}
+
 // Close terminates the current connection and stop any further attempts.
 func (c *client) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c.shutdown(ctx)
+	return nil
+}
+
+// shutdown stops any further connection attempts, sends unbind on the
+// current connection, if any, and terminates it upon UnbindResp or
+// when ctx is done, returning ctx.Err(). It returns ErrNotConnected if
+// the connection is lost before the response arrives.
+func (c *client) shutdown(ctx context.Context) error {
+	var err error
 	c.once.Do(func() {
 		close(c.stop)
-		if err := c.conn.Write(pdu.NewUnbind()); err == nil {
-			select {
-			case <-c.inbox: // TODO: validate UnbindResp
-			case <-time.After(time.Second):
-			}
+		p := pdu.NewUnbind()
+		atomic.StoreUint32(&c.unbindSeq, p.Header().Seq)
+		if c.conn.Write(p) == nil {
+			err = c.waitUnbind(ctx)
 		}
 		c.conn.Close()
 	})
-	return nil
+	return err
+}
+
+// waitUnbind waits for the UnbindResp of shutdown.
+func (c *client) waitUnbind(ctx context.Context) error {
+	inbox := c.getInbox()
+	for {
+		select {
+		case <-c.unbound:
+			return nil
+		case _, ok := <-inbox:
+			if !ok {
+				return ErrNotConnected
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
 // trysleep for the given duration, or return if Close is called.
@@ -280,10 +458,14 @@ func (c *client) respTimeout() <-chan time.Time {
 	return time.After(c.RespTimeout)
 }
 
-// bind attempts to bind the connection.
-func bind(c Conn, p pdu.Body) (pdu.Body, error) {
+// bind attempts to bind the connection with the given interface
+// version, V34 if 0.
+func bind(c Conn, p pdu.Body, version uint8) (pdu.Body, error) {
+	if version == 0 {
+		version = V34
+	}
 	f := p.Fields()
-	f.Set(pdufield.InterfaceVersion, 0x34)
+	f.Set(pdufield.InterfaceVersion, version)
 	err := c.Write(p)
 	if err != nil {
 		return nil, err
@@ -298,3 +480,21 @@ func bind(c Conn, p pdu.Body) (pdu.Body, error) {
 	}
 	return resp, nil
 }
+
+// negotiate returns the interface version to use with the SMSC that
+// sent the bind response resp, given the requested version: the
+// lowest of both. SMSCs that omit sc_interface_version are assumed to
+// speak V34 at most.
+func negotiate(version uint8, resp pdu.Body) uint8 {
+	if version == 0 {
+		version = V34
+	}
+	sc, ok := resp.TLVFields().Uint8(pdutlv.TagScInterfaceVersion)
+	if !ok {
+		sc = V34
+	}
+	if sc < version {
+		return sc
+	}
+	return version
+}
@@ -7,13 +7,14 @@ package smpp
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"io"
 	"net"
 	"sync"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
 )
 
 var (
@@ -26,6 +27,10 @@ var (
 
 	// ErrTimeout is returned when we've reached timeout while waiting for response.
 	ErrTimeout = errors.New("timeout waiting for response")
+
+	// ErrShutdown is returned on attempts to send requests during or
+	// after Shutdown.
+	ErrShutdown = errors.New("shutting down")
 )
 
 // Conn is an SMPP connection.
@@ -53,6 +58,36 @@ type Closer interface {
 	Close() error
 }
 
+// ContextWriter is the interface that wraps the WriteContext method.
+// Connections returned by WrapConn middleware may implement it to get
+// the context of the requests, e.g. for tracing.
+type ContextWriter interface {
+	// WriteContext is like Write, with the context of the
+	// request the PDU is written for.
+	WriteContext(ctx context.Context, w pdu.Body) error
+}
+
+// WriteContext writes w to c using WriteContext if c implements
+// ContextWriter, or Write otherwise. Middleware uses it to pass the
+// context on to the connection it wraps.
+func WriteContext(ctx context.Context, c Writer, w pdu.Body) error {
+	if cw, ok := c.(ContextWriter); ok {
+		return cw.WriteContext(ctx, w)
+	}
+	return c.Write(w)
+}
+
+// ChainConn returns a WrapConn function that applies the given
+// middleware in order, the first one being the outermost.
+func ChainConn(mw ...func(Conn) Conn) func(Conn) Conn {
+	return func(c Conn) Conn {
+		for i := len(mw) - 1; i >= 0; i-- {
+			c = mw[i](c)
+		}
+		return c
+	}
+}
+
 // Dial dials to the SMPP server and returns a Conn, or error.
 // TLS is only used if provided.
 func Dial(addr string, TLS *tls.Config) (Conn, error) {
@@ -66,12 +101,7 @@ func Dial(addr string, TLS *tls.Config) (Conn, error) {
 	if TLS != nil {
 		fd = tls.Client(fd, TLS)
 	}
-	c := &conn{
-		rwc: fd,
-		r:   bufio.NewReader(fd),
-		w:   bufio.NewWriter(fd),
-	}
-	return c, nil
+	return newConn(fd), nil
 }
 
 // conn provides the basics of a single client connection and
@@ -82,6 +112,14 @@ type conn struct {
 	w   *bufio.Writer
 }
 
+func newConn(fd net.Conn) *conn {
+	return &conn{
+		rwc: fd,
+		r:   bufio.NewReader(fd),
+		w:   bufio.NewWriter(fd),
+	}
+}
+
 // Read implements the Conn interface.
 func (c *conn) Read() (pdu.Body, error) {
 	return pdu.Decode(c.r)
@@ -112,8 +150,9 @@ func (c *conn) Close() error {
 // If no Conn is available, any attempt to Read/Write/Close
 // returns ErrNotConnected.
 type connSwitch struct {
-	mu sync.Mutex
-	c  Conn
+	mu      sync.Mutex
+	c       Conn
+	metrics Metrics // optional.
 }
 
 // Set sets the underlying Conn with the given one.
@@ -135,17 +174,30 @@ func (cs *connSwitch) Read() (pdu.Body, error) {
 	if conn == nil {
 		return nil, ErrNotConnected
 	}
-	return conn.Read()
+	p, err := conn.Read()
+	if err == nil && cs.metrics != nil {
+		cs.metrics.PDUReceived(p.Header().ID)
+	}
+	return p, err
 }
 
 // Write implements the Conn interface.
 func (cs *connSwitch) Write(w pdu.Body) error {
+	return cs.WriteContext(context.Background(), w)
+}
+
+// WriteContext implements the ContextWriter interface.
+func (cs *connSwitch) WriteContext(ctx context.Context, w pdu.Body) error {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 	if cs.c == nil {
 		return ErrNotConnected
 	}
-	return cs.c.Write(w)
+	err := WriteContext(ctx, cs.c, w)
+	if err == nil && cs.metrics != nil {
+		cs.metrics.PDUSent(w.Header().ID)
+	}
+	return err
 }
 
 // Close implements the Conn interface.
@@ -3,4 +3,8 @@
 // found in the LICENSE file.
 
 // Package smpp is an implementation of the SMPP 3.4 protocol.
+//
+// SMPP 5.0 is opt-in: clients that set InterfaceVersion to V50 bind
+// with it, and use its features such as cell broadcasts only when the
+// SMSC confirms it in the sc_interface_version of the bind response.
 package smpp
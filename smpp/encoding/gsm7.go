@@ -82,6 +82,37 @@ func ValidateGSM7String(text string) []rune {
 	return invalidChars
 }
 
+// Returns the characters, in the given text, that can not be represented in GSM 7-bit encoding
+// with the locking and single shift tables of the given national languages.
+func ValidateGSM7NationalString(text string, locking, single Language) []rune {
+	invalidChars := make([]rune, 0, 4)
+	for _, r := range text {
+		if GSM7NationalRuneLen(r, locking, single) == 0 {
+			invalidChars = append(invalidChars, r)
+		}
+	}
+	return invalidChars
+}
+
+// GSM7RuneLen returns the number of septets needed to encode r in GSM
+// 7-bit: two for characters of the extension table, which are escaped,
+// and zero for characters that can not be represented.
+func GSM7RuneLen(r rune) int {
+	return GSM7NationalRuneLen(r, DefaultLanguage, DefaultLanguage)
+}
+
+// GSM7NationalRuneLen is like GSM7RuneLen but uses the locking and
+// single shift tables of the given national languages.
+func GSM7NationalRuneLen(r rune, locking, single Language) int {
+	if _, ok := lockingTable(locking).forward[r]; ok {
+		return 1
+	}
+	if _, ok := singleTable(single).forward[r]; ok {
+		return 2
+	}
+	return 0
+}
+
 // Returns the bytes, in the given buffer, that are outside of the GSM 7-bit encoding range.
 func ValidateGSM7Buffer(buffer []byte) []byte {
 	invalidBytes := make([]byte, 0, 4)
@@ -114,31 +145,58 @@ func GSM7(packed bool) encoding.Encoding {
 	return gsm7Encoding{packed: packed}
 }
 
+// GSM7National returns a GSM 7-bit Bit Encoding using the locking
+// shift table of the locking language instead of the default alphabet,
+// and the single shift table of the single language instead of the
+// default extension table. Languages without the requested table use
+// the default one.
+//
+// The languages must be signalled to the recipient in the user data
+// header, see the pdutext package.
+func GSM7National(packed bool, locking, single Language) encoding.Encoding {
+	return gsm7Encoding{packed: packed, locking: locking, single: single}
+}
+
 type gsm7Encoding struct {
-	packed bool
+	packed  bool
+	locking Language
+	single  Language
 }
 
 func (g gsm7Encoding) NewDecoder() *encoding.Decoder {
 	return &encoding.Decoder{Transformer: &gsm7Decoder{
-		packed: g.packed,
+		packed:  g.packed,
+		locking: lockingTable(g.locking),
+		single:  singleTable(g.single),
 	}}
 }
 
 func (g gsm7Encoding) NewEncoder() *encoding.Encoder {
 	return &encoding.Encoder{Transformer: &gsm7Encoder{
-		packed: g.packed,
+		packed:  g.packed,
+		locking: lockingTable(g.locking),
+		single:  singleTable(g.single),
 	}}
 }
 
 func (g gsm7Encoding) String() string {
+	name := "GSM 7-bit (Unpacked)"
 	if g.packed {
-		return "GSM 7-bit (Packed)"
+		name = "GSM 7-bit (Packed)"
+	}
+	if g.locking != DefaultLanguage {
+		name += " " + g.locking.String() + " locking shift"
+	}
+	if g.single != DefaultLanguage {
+		name += " " + g.single.String() + " single shift"
 	}
-	return "GSM 7-bit (Unpacked)"
+	return name
 }
 
 type gsm7Decoder struct {
-	packed bool
+	packed  bool
+	locking shiftTable
+	single  shiftTable
 }
 
 func (g *gsm7Decoder) Reset() {
@@ -219,12 +277,12 @@ func (g *gsm7Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, er
 				return 0, 0, ErrInvalidByte
 			}
 			e := septets[nSeptet]
-			if r, ok := reverseEscape[e]; ok {
+			if r, ok := g.single.reverse[e]; ok {
 				builder.WriteRune(r)
 			} else {
 				return 0, 0, ErrInvalidByte
 			}
-		} else if r, ok := reverseLookup[b]; ok {
+		} else if r, ok := g.locking.reverse[b]; ok {
 			builder.WriteRune(r)
 		} else {
 			return 0, 0, ErrInvalidByte
@@ -245,7 +303,9 @@ func (g *gsm7Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, er
 }
 
 type gsm7Encoder struct {
-	packed bool
+	packed  bool
+	locking shiftTable
+	single  shiftTable
 }
 
 func (g *gsm7Encoder) Reset() {
@@ -260,9 +320,9 @@ func (g *gsm7Encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, er
 	text := string(src) // work with []rune (a.k.a string) instead of []byte
 	septets := make([]byte, 0, len(text))
 	for _, r := range text {
-		if v, ok := forwardLookup[r]; ok {
+		if v, ok := g.locking.forward[r]; ok {
 			septets = append(septets, v)
-		} else if v, ok := forwardEscape[r]; ok {
+		} else if v, ok := g.single.forward[r]; ok {
 			septets = append(septets, escapeSequence, v)
 		} else {
 			return 0, 0, ErrInvalidCharacter
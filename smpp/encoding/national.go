@@ -0,0 +1,542 @@
+package encoding
+
+// Language is a national language of 3GPP TS 23.038, used to select the
+// locking and single shift tables of the GSM 7-bit encoding. Its value
+// is the national language identifier in the user data header.
+type Language uint8
+
+// Supported national languages.
+const (
+	DefaultLanguage Language = 0x00 // GSM 7-bit default alphabet.
+	Turkish         Language = 0x01
+	Spanish         Language = 0x02 // Single shift table only.
+	Portuguese      Language = 0x03
+	Bengali         Language = 0x04
+	Gujarati        Language = 0x05
+	Hindi           Language = 0x06
+	Kannada         Language = 0x07
+	Malayalam       Language = 0x08
+	Oriya           Language = 0x09
+	Punjabi         Language = 0x0a
+	Tamil           Language = 0x0b
+	Telugu          Language = 0x0c
+	Urdu            Language = 0x0d
+)
+
+var languageNames = map[Language]string{
+	DefaultLanguage: "Default",
+	Turkish:         "Turkish",
+	Spanish:         "Spanish",
+	Portuguese:      "Portuguese",
+	Bengali:         "Bengali",
+	Gujarati:        "Gujarati",
+	Hindi:           "Hindi",
+	Kannada:         "Kannada",
+	Malayalam:       "Malayalam",
+	Oriya:           "Oriya",
+	Punjabi:         "Punjabi",
+	Tamil:           "Tamil",
+	Telugu:          "Telugu",
+	Urdu:            "Urdu",
+}
+
+// String implements the Stringer interface.
+func (l Language) String() string {
+	return languageNames[l]
+}
+
+// HasLockingShift reports whether l has a locking shift table, which
+// replaces the default alphabet.
+func (l Language) HasLockingShift() bool {
+	_, ok := lockingTables[l]
+	return ok || l == DefaultLanguage
+}
+
+// HasSingleShift reports whether l has a single shift table, which
+// replaces the default extension table.
+func (l Language) HasSingleShift() bool {
+	_, ok := singleTables[l]
+	return ok || l == DefaultLanguage
+}
+
+// shiftTable is a GSM 7-bit character table in both directions.
+type shiftTable struct {
+	forward map[rune]byte
+	reverse map[byte]rune
+}
+
+var (
+	defaultLocking = shiftTable{forwardLookup, reverseLookup}
+	defaultSingle  = shiftTable{forwardEscape, reverseEscape}
+	lockingTables  = map[Language]shiftTable{}
+	singleTables   = map[Language]shiftTable{}
+)
+
+func init() {
+	for l, t := range map[Language]map[byte]rune{
+		Turkish:    turkishLocking,
+		Portuguese: portugueseLocking,
+		Bengali:    bengaliLocking,
+		Gujarati:   gujaratiLocking,
+		Hindi:      hindiLocking,
+		Kannada:    kannadaLocking,
+		Malayalam:  malayalamLocking,
+		Oriya:      oriyaLocking,
+		Punjabi:    punjabiLocking,
+		Tamil:      tamilLocking,
+		Telugu:     teluguLocking,
+		Urdu:       urduLocking,
+	} {
+		lockingTables[l] = newShiftTable(t)
+	}
+	for l, t := range map[Language]map[byte]rune{
+		Turkish:    turkishSingle,
+		Spanish:    spanishSingle,
+		Portuguese: portugueseSingle,
+		Bengali:    bengaliSingle,
+		Gujarati:   gujaratiSingle,
+		Hindi:      hindiSingle,
+		Kannada:    kannadaSingle,
+		Malayalam:  malayalamSingle,
+		Oriya:      oriyaSingle,
+		Punjabi:    punjabiSingle,
+		Tamil:      tamilSingle,
+		Telugu:     teluguSingle,
+		Urdu:       urduSingle,
+	} {
+		singleTables[l] = newShiftTable(t)
+	}
+}
+
+// newShiftTable returns the table for the given reverse lookup. Some
+// tables have duplicate characters, which are encoded with the lowest
+// value.
+func newShiftTable(reverse map[byte]rune) shiftTable {
+	forward := make(map[rune]byte, len(reverse))
+	for b, r := range reverse {
+		if v, ok := forward[r]; !ok || b < v {
+			forward[r] = b
+		}
+	}
+	return shiftTable{forward, reverse}
+}
+
+// lockingTable returns the locking shift table of l, or the default
+// alphabet.
+func lockingTable(l Language) shiftTable {
+	if t, ok := lockingTables[l]; ok {
+		return t
+	}
+	return defaultLocking
+}
+
+// singleTable returns the single shift table of l, or the default
+// extension table.
+func singleTable(l Language) shiftTable {
+	if t, ok := singleTables[l]; ok {
+		return t
+	}
+	return defaultSingle
+}
+
+/*
+National language locking and single shift tables
+
+Source: 3GPP TS 23.038, section 6.2.1 and 6.2.2.
+*/
+
+// Turkish locking shift table.
+var turkishLocking = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '€', 0x05: 'é', 0x06: 'ù', 0x07: 'ı',
+	0x08: 'ò', 0x09: 'Ç', 0x0a: '\n', 0x0b: 'Ğ', 0x0c: 'ğ', 0x0d: '\r', 0x0e: 'Å', 0x0f: 'å',
+	0x10: 'Δ', 0x11: '_', 0x12: 'Φ', 0x13: 'Γ', 0x14: 'Λ', 0x15: 'Ω', 0x16: 'Π', 0x17: 'Ψ',
+	0x18: 'Σ', 0x19: 'Θ', 0x1a: 'Ξ', 0x1c: 'Ş', 0x1d: 'ş', 0x1e: 'ß', 0x1f: 'É', 0x20: ' ',
+	0x21: '!', 0x22: '"', 0x23: '#', 0x24: '¤', 0x25: '%', 0x26: '&', 0x27: '\'', 0x28: '(',
+	0x29: ')', 0x2a: '*', 0x2b: '+', 0x2c: ',', 0x2d: '-', 0x2e: '.', 0x2f: '/', 0x30: '0',
+	0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8',
+	0x39: '9', 0x3a: ':', 0x3b: ';', 0x3c: '<', 0x3d: '=', 0x3e: '>', 0x3f: '?', 0x40: 'İ',
+	0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H',
+	0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P',
+	0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X',
+	0x59: 'Y', 0x5a: 'Z', 0x5b: 'Ä', 0x5c: 'Ö', 0x5d: 'Ñ', 0x5e: 'Ü', 0x5f: '§', 0x60: 'ç',
+	0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g', 0x68: 'h',
+	0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p',
+	0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x',
+	0x79: 'y', 0x7a: 'z', 0x7b: 'ä', 0x7c: 'ö', 0x7d: 'ñ', 0x7e: 'ü', 0x7f: 'à',
+}
+
+// Turkish single shift table.
+var turkishSingle = map[byte]rune{
+	0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+	0x40: '|', 0x47: 'Ğ', 0x49: 'İ', 0x53: 'Ş', 0x63: 'ç', 0x65: '€', 0x67: 'ğ', 0x69: 'ı',
+	0x73: 'ş',
+}
+
+// Spanish single shift table.
+var spanishSingle = map[byte]rune{
+	0x09: 'ç', 0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~',
+	0x3e: ']', 0x40: '|', 0x41: 'Á', 0x49: 'Í', 0x4f: 'Ó', 0x55: 'Ú', 0x61: 'á', 0x65: '€',
+	0x69: 'í', 0x6f: 'ó', 0x75: 'ú',
+}
+
+// Portuguese locking shift table.
+var portugueseLocking = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: 'ê', 0x05: 'é', 0x06: 'ú', 0x07: 'í',
+	0x08: 'ó', 0x09: 'ç', 0x0a: '\n', 0x0b: 'Ô', 0x0c: 'ô', 0x0d: '\r', 0x0e: 'Á', 0x0f: 'á',
+	0x10: 'Δ', 0x11: '_', 0x12: 'ª', 0x13: 'Ç', 0x14: 'À', 0x15: '∞', 0x16: '^', 0x17: '\\',
+	0x18: '€', 0x19: 'Ó', 0x1a: '|', 0x1c: 'Â', 0x1d: 'â', 0x1e: 'Ê', 0x1f: 'É', 0x20: ' ',
+	0x21: '!', 0x22: '"', 0x23: '#', 0x24: 'º', 0x25: '%', 0x26: '&', 0x27: '\'', 0x28: '(',
+	0x29: ')', 0x2a: '*', 0x2b: '+', 0x2c: ',', 0x2d: '-', 0x2e: '.', 0x2f: '/', 0x30: '0',
+	0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8',
+	0x39: '9', 0x3a: ':', 0x3b: ';', 0x3c: '<', 0x3d: '=', 0x3e: '>', 0x3f: '?', 0x40: 'Í',
+	0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H',
+	0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P',
+	0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X',
+	0x59: 'Y', 0x5a: 'Z', 0x5b: 'Ã', 0x5c: 'Õ', 0x5d: 'Ú', 0x5e: 'Ü', 0x5f: '§', 0x60: '~',
+	0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g', 0x68: 'h',
+	0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p',
+	0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x',
+	0x79: 'y', 0x7a: 'z', 0x7b: 'ã', 0x7c: 'õ', 0x7d: '`', 0x7e: 'ü', 0x7f: 'à',
+}
+
+// Portuguese single shift table.
+var portugueseSingle = map[byte]rune{
+	0x05: 'ê', 0x09: 'ç', 0x0a: '\f', 0x0b: 'Ô', 0x0c: 'ô', 0x0e: 'Á', 0x0f: 'á', 0x12: 'Φ',
+	0x13: 'Γ', 0x14: '^', 0x15: 'Ω', 0x16: 'Π', 0x17: 'Ψ', 0x18: 'Σ', 0x19: 'Θ', 0x28: '{',
+	0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'À', 0x49: 'Í',
+	0x4f: 'Ó', 0x55: 'Ú', 0x5b: 'Ã', 0x5c: 'Õ', 0x61: 'Â', 0x65: '€', 0x69: 'í', 0x6f: 'ó',
+	0x75: 'ú', 0x7b: 'ã', 0x7c: 'õ', 0x7f: 'â',
+}
+
+// Bengali locking shift table.
+var bengaliLocking = map[byte]rune{
+	0x00: '\u0981', 0x01: '\u0982', 0x02: '\u0983', 0x03: 'অ', 0x04: 'আ', 0x05: 'ই', 0x06: 'ঈ', 0x07: 'উ',
+	0x08: 'ঊ', 0x09: 'ঋ', 0x0a: '\n', 0x0b: 'ঌ', 0x0d: '\r', 0x0f: 'এ', 0x10: 'ঐ', 0x13: 'ও',
+	0x14: 'ঔ', 0x15: 'ক', 0x16: 'খ', 0x17: 'গ', 0x18: 'ঘ', 0x19: 'ঙ', 0x1a: 'চ', 0x1c: 'ছ',
+	0x1d: 'জ', 0x1e: 'ঝ', 0x1f: 'ঞ', 0x20: ' ', 0x21: '!', 0x22: 'ট', 0x23: 'ঠ', 0x24: 'ড',
+	0x25: 'ঢ', 0x26: 'ণ', 0x27: 'ত', 0x28: ')', 0x29: '(', 0x2a: 'থ', 0x2b: 'দ', 0x2c: ',',
+	0x2d: 'ধ', 0x2e: '.', 0x2f: 'ন', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4',
+	0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';', 0x3d: 'প',
+	0x3e: 'ফ', 0x3f: '?', 0x40: 'ব', 0x41: 'ভ', 0x42: 'ম', 0x43: 'য', 0x44: 'র', 0x46: 'ল',
+	0x4a: 'শ', 0x4b: 'ষ', 0x4c: 'স', 0x4d: 'হ', 0x4e: '\u09bc', 0x4f: 'ঽ', 0x50: '\u09be', 0x51: '\u09bf',
+	0x52: '\u09c0', 0x53: '\u09c1', 0x54: '\u09c2', 0x55: '\u09c3', 0x56: '\u09c4', 0x59: '\u09c7', 0x5a: '\u09c8', 0x5d: '\u09cb',
+	0x5e: '\u09cc', 0x5f: '\u09cd', 0x60: '\u09ce', 0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e',
+	0x66: 'f', 0x67: 'g', 0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm',
+	0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u',
+	0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: '\u09d7', 0x7c: 'ড়', 0x7d: 'ঢ়',
+	0x7e: 'ৰ', 0x7f: 'ৱ',
+}
+
+// Bengali single shift table.
+var bengaliSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '০', 0x1a: '১', 0x1c: '২', 0x1d: '৩', 0x1e: '৪', 0x1f: '৫', 0x20: '৬', 0x21: '৭',
+	0x22: '৮', 0x23: '৯', 0x24: 'য়', 0x25: 'ৠ', 0x26: 'ৡ', 0x27: '\u09e2', 0x28: '{', 0x29: '}',
+	0x2a: '\u09e3', 0x2b: '৲', 0x2c: '৳', 0x2d: '৴', 0x2e: '৵', 0x2f: '\\', 0x30: '৶', 0x31: '৷',
+	0x32: '৸', 0x33: '৹', 0x34: '৺', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A',
+	0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I',
+	0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+	0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y',
+	0x5a: 'Z', 0x65: '€',
+}
+
+// Gujarati locking shift table.
+var gujaratiLocking = map[byte]rune{
+	0x00: '\u0a81', 0x01: '\u0a82', 0x02: '\u0a83', 0x03: 'અ', 0x04: 'આ', 0x05: 'ઇ', 0x06: 'ઈ', 0x07: 'ઉ',
+	0x08: 'ઊ', 0x09: 'ઋ', 0x0a: '\n', 0x0b: 'ઌ', 0x0c: 'ઍ', 0x0d: '\r', 0x0f: 'એ', 0x10: 'ઐ',
+	0x11: 'ઑ', 0x13: 'ઓ', 0x14: 'ઔ', 0x15: 'ક', 0x16: 'ખ', 0x17: 'ગ', 0x18: 'ઘ', 0x19: 'ઙ',
+	0x1a: 'ચ', 0x1c: 'છ', 0x1d: 'જ', 0x1e: 'ઝ', 0x1f: 'ઞ', 0x20: ' ', 0x21: '!', 0x22: 'ટ',
+	0x23: 'ઠ', 0x24: 'ડ', 0x25: 'ઢ', 0x26: 'ણ', 0x27: 'ત', 0x28: ')', 0x29: '(', 0x2a: 'થ',
+	0x2b: 'દ', 0x2c: ',', 0x2d: 'ધ', 0x2e: '.', 0x2f: 'ન', 0x30: '0', 0x31: '1', 0x32: '2',
+	0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':',
+	0x3b: ';', 0x3d: 'પ', 0x3e: 'ફ', 0x3f: '?', 0x40: 'બ', 0x41: 'ભ', 0x42: 'મ', 0x43: 'ય',
+	0x44: 'ર', 0x46: 'લ', 0x47: 'ળ', 0x49: 'વ', 0x4a: 'શ', 0x4b: 'ષ', 0x4c: 'સ', 0x4d: 'હ',
+	0x4e: '\u0abc', 0x4f: 'ઽ', 0x50: '\u0abe', 0x51: '\u0abf', 0x52: '\u0ac0', 0x53: '\u0ac1', 0x54: '\u0ac2', 0x55: '\u0ac3',
+	0x56: '\u0ac4', 0x57: '\u0ac5', 0x59: '\u0ac7', 0x5a: '\u0ac8', 0x5b: '\u0ac9', 0x5d: '\u0acb', 0x5e: '\u0acc', 0x5f: '\u0acd',
+	0x60: 'ૐ', 0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+	0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o',
+	0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w',
+	0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: 'ૠ', 0x7c: 'ૡ', 0x7d: '\u0ae2', 0x7e: '\u0ae3', 0x7f: '૱',
+}
+
+// Gujarati single shift table.
+var gujaratiSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '।', 0x1a: '॥', 0x1c: '૦', 0x1d: '૧', 0x1e: '૨', 0x1f: '૩', 0x20: '૪', 0x21: '૫',
+	0x22: '૬', 0x23: '૭', 0x24: '૮', 0x25: '૯', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[',
+	0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E',
+	0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M',
+	0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U',
+	0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+}
+
+// Hindi locking shift table.
+var hindiLocking = map[byte]rune{
+	0x00: '\u0981', 0x01: '\u0982', 0x02: '\u0983', 0x03: 'अ', 0x04: 'आ', 0x05: 'इ', 0x06: 'ई', 0x07: 'उ',
+	0x08: 'ऊ', 0x09: 'ऋ', 0x0a: '\n', 0x0b: 'ऌ', 0x0c: 'ऍ', 0x0d: '\r', 0x0e: 'ऎ', 0x0f: 'ए',
+	0x10: 'ऐ', 0x11: 'ऑ', 0x12: 'ऒ', 0x13: 'ओ', 0x14: 'औ', 0x15: 'क', 0x16: 'ख', 0x17: 'ग',
+	0x18: 'घ', 0x19: 'ङ', 0x1a: 'च', 0x1c: 'छ', 0x1d: 'ज', 0x1e: 'झ', 0x1f: 'ञ', 0x20: ' ',
+	0x21: '!', 0x22: 'ट', 0x23: 'ठ', 0x24: 'ड', 0x25: 'ढ', 0x26: 'ण', 0x27: 'त', 0x28: ')',
+	0x29: '(', 0x2a: 'थ', 0x2b: 'द', 0x2c: ',', 0x2d: 'ध', 0x2e: '.', 0x2f: 'न', 0x30: '0',
+	0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8',
+	0x39: '9', 0x3a: ':', 0x3b: ';', 0x3c: 'ऩ', 0x3d: 'प', 0x3e: 'फ', 0x3f: '?', 0x40: 'ब',
+	0x41: 'भ', 0x42: 'म', 0x43: 'य', 0x44: 'र', 0x45: 'ऱ', 0x46: 'ल', 0x47: 'ळ', 0x48: 'ऴ',
+	0x49: 'व', 0x4a: 'श', 0x4b: 'ष', 0x4c: 'स', 0x4d: 'ह', 0x4e: '\u093c', 0x4f: 'ऽ', 0x50: '\u093e',
+	0x51: '\u093f', 0x52: '\u0940', 0x53: '\u0941', 0x54: '\u0942', 0x55: '\u0943', 0x56: '\u0944', 0x57: '\u0945', 0x58: '\u0946',
+	0x59: '\u0947', 0x5a: '\u0948', 0x5b: '\u0949', 0x5c: '\u094a', 0x5d: '\u094b', 0x5e: '\u094c', 0x5f: '\u094d', 0x60: 'ॐ',
+	0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g', 0x68: 'h',
+	0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p',
+	0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x',
+	0x79: 'y', 0x7a: 'z', 0x7b: 'ॲ', 0x7c: 'ॻ', 0x7d: 'ॼ', 0x7e: 'ॾ', 0x7f: 'ॿ',
+}
+
+// Hindi single shift table.
+var hindiSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '।', 0x1a: '॥', 0x1c: '०', 0x1d: '१', 0x1e: '२', 0x1f: '३', 0x20: '४', 0x21: '५',
+	0x22: '६', 0x23: '७', 0x24: '८', 0x25: '९', 0x26: '\u0951', 0x27: '\u0952', 0x28: '{', 0x29: '}',
+	0x2a: '\u0953', 0x2b: '\u0954', 0x2c: 'क़', 0x2d: 'ख़', 0x2e: 'ग़', 0x2f: '\\', 0x30: 'ज़', 0x31: 'ड़',
+	0x32: 'ढ़', 0x33: 'फ़', 0x34: 'य़', 0x35: 'ॠ', 0x36: 'ॡ', 0x37: '\u0962', 0x38: '\u0963', 0x39: '॰',
+	0x3a: 'ॱ', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C',
+	0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K',
+	0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S',
+	0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+}
+
+// Kannada locking shift table.
+var kannadaLocking = map[byte]rune{
+	0x01: '\u0c82', 0x02: '\u0c83', 0x03: 'ಅ', 0x04: 'ಆ', 0x05: 'ಇ', 0x06: 'ಈ', 0x07: 'ಉ', 0x08: 'ಊ',
+	0x09: 'ಋ', 0x0a: '\n', 0x0b: 'ಌ', 0x0d: '\r', 0x0e: 'ಎ', 0x0f: 'ಏ', 0x10: 'ಐ', 0x12: 'ಒ',
+	0x13: 'ಓ', 0x14: 'ಔ', 0x15: 'ಕ', 0x16: 'ಖ', 0x17: 'ಗ', 0x18: 'ಘ', 0x19: 'ಙ', 0x1a: 'ಚ',
+	0x1c: 'ಛ', 0x1d: 'ಜ', 0x1e: 'ಝ', 0x1f: 'ಞ', 0x20: ' ', 0x21: '!', 0x22: 'ಟ', 0x23: 'ಠ',
+	0x24: 'ಡ', 0x25: 'ಢ', 0x26: 'ಣ', 0x27: 'ತ', 0x28: ')', 0x29: '(', 0x2a: 'ಥ', 0x2b: 'ದ',
+	0x2c: ',', 0x2d: 'ಧ', 0x2e: '.', 0x2f: 'ನ', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3',
+	0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';',
+	0x3d: 'ಪ', 0x3e: 'ಫ', 0x3f: '?', 0x40: 'ಬ', 0x41: 'ಭ', 0x42: 'ಮ', 0x43: 'ಯ', 0x44: 'ರ',
+	0x45: 'ಱ', 0x46: 'ಲ', 0x47: 'ಳ', 0x49: 'ವ', 0x4a: 'ಶ', 0x4b: 'ಷ', 0x4c: 'ಸ', 0x4d: 'ಹ',
+	0x4e: '\u0cbc', 0x4f: 'ಽ', 0x50: '\u0cbe', 0x51: '\u0cbf', 0x52: '\u0cc0', 0x53: '\u0cc1', 0x54: '\u0cc2', 0x55: '\u0cc3',
+	0x56: '\u0cc4', 0x58: '\u0cc6', 0x59: '\u0cc7', 0x5a: '\u0cc8', 0x5c: '\u0cca', 0x5d: '\u0ccb', 0x5e: '\u0ccc', 0x5f: '\u0ccd',
+	0x60: '\u0cd5', 0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+	0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o',
+	0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w',
+	0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: '\u0cd6', 0x7c: 'ೠ', 0x7d: 'ೡ', 0x7e: '\u0ce2', 0x7f: '\u0ce3',
+}
+
+// Kannada single shift table.
+var kannadaSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '।', 0x1a: '॥', 0x1c: '೦', 0x1d: '೧', 0x1e: '೨', 0x1f: '೩', 0x20: '೪', 0x21: '೫',
+	0x22: '೬', 0x23: '೭', 0x24: '೮', 0x25: '೯', 0x26: 'ೞ', 0x27: 'ೱ', 0x28: '{', 0x29: '}',
+	0x2a: 'ೲ', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B',
+	0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J',
+	0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q', 0x52: 'R',
+	0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z',
+	0x65: '€',
+}
+
+// Malayalam locking shift table.
+var malayalamLocking = map[byte]rune{
+	0x01: '\u0d02', 0x02: '\u0d03', 0x03: 'അ', 0x04: 'ആ', 0x05: 'ഇ', 0x06: 'ഈ', 0x07: 'ഉ', 0x08: 'ഊ',
+	0x09: 'ഋ', 0x0a: '\n', 0x0b: 'ഌ', 0x0d: '\r', 0x0e: 'എ', 0x0f: 'ഏ', 0x10: 'ഐ', 0x12: 'ഒ',
+	0x13: 'ഓ', 0x14: 'ഔ', 0x15: 'ക', 0x16: 'ഖ', 0x17: 'ഗ', 0x18: 'ഘ', 0x19: 'ങ', 0x1a: 'ച',
+	0x1c: 'ഛ', 0x1d: 'ജ', 0x1e: 'ഝ', 0x1f: 'ഞ', 0x20: ' ', 0x21: '!', 0x22: 'ട', 0x23: 'ഠ',
+	0x24: 'ഡ', 0x25: 'ഢ', 0x26: 'ണ', 0x27: 'ത', 0x28: ')', 0x29: '(', 0x2a: 'ഥ', 0x2b: 'ദ',
+	0x2c: ',', 0x2d: 'ധ', 0x2e: '.', 0x2f: 'ന', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3',
+	0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';',
+	0x3d: 'പ', 0x3e: 'ഫ', 0x3f: '?', 0x40: 'ബ', 0x41: 'ഭ', 0x42: 'മ', 0x43: 'യ', 0x44: 'ര',
+	0x45: 'റ', 0x46: 'ല', 0x47: 'ള', 0x48: 'ഴ', 0x49: 'വ', 0x4a: 'ശ', 0x4b: 'ഷ', 0x4c: 'സ',
+	0x4d: 'ഹ', 0x4f: 'ഽ', 0x50: '\u0d3e', 0x51: '\u0d3f', 0x52: '\u0d40', 0x53: '\u0d41', 0x54: '\u0d42', 0x55: '\u0d43',
+	0x56: '\u0d44', 0x58: '\u0d46', 0x59: '\u0d47', 0x5a: '\u0d48', 0x5c: '\u0d4a', 0x5d: '\u0d4b', 0x5e: '\u0d4c', 0x5f: '\u0d4d',
+	0x60: '\u0d57', 0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+	0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o',
+	0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w',
+	0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: 'ൠ', 0x7c: 'ൡ', 0x7d: '\u0d62', 0x7e: '\u0d63', 0x7f: '൹',
+}
+
+// Malayalam single shift table.
+var malayalamSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '।', 0x1a: '॥', 0x1c: '൦', 0x1d: '൧', 0x1e: '൨', 0x1f: '൩', 0x20: '൪', 0x21: '൫',
+	0x22: '൬', 0x23: '൭', 0x24: '൮', 0x25: '൯', 0x26: '൰', 0x27: '൱', 0x28: '{', 0x29: '}',
+	0x2a: '൲', 0x2b: '൳', 0x2c: '൴', 0x2d: '൵', 0x2e: 'ൺ', 0x2f: '\\', 0x30: 'ൻ', 0x31: 'ർ',
+	0x32: 'ൽ', 0x33: 'ൾ', 0x34: 'ൿ', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A',
+	0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I',
+	0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+	0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y',
+	0x5a: 'Z', 0x65: '€',
+}
+
+// Oriya locking shift table.
+var oriyaLocking = map[byte]rune{
+	0x00: '\u0b01', 0x01: '\u0b02', 0x02: '\u0b03', 0x03: 'ଅ', 0x04: 'ଆ', 0x05: 'ଇ', 0x06: 'ଈ', 0x07: 'ଉ',
+	0x08: 'ଊ', 0x09: 'ଋ', 0x0a: '\n', 0x0b: 'ଌ', 0x0d: '\r', 0x0f: 'ଏ', 0x10: 'ଐ', 0x13: 'ଓ',
+	0x14: 'ଔ', 0x15: 'କ', 0x16: 'ଖ', 0x17: 'ଗ', 0x18: 'ଘ', 0x19: 'ଙ', 0x1a: 'ଚ', 0x1c: 'ଛ',
+	0x1d: 'ଜ', 0x1e: 'ଝ', 0x1f: 'ଞ', 0x20: ' ', 0x21: '!', 0x22: 'ଟ', 0x23: 'ଠ', 0x24: 'ଡ',
+	0x25: 'ଢ', 0x26: 'ଣ', 0x27: 'ତ', 0x28: ')', 0x29: '(', 0x2a: 'ଥ', 0x2b: 'ଦ', 0x2c: ',',
+	0x2d: 'ଧ', 0x2e: '.', 0x2f: 'ନ', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4',
+	0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';', 0x3d: 'ପ',
+	0x3e: 'ଫ', 0x3f: '?', 0x40: 'ବ', 0x41: 'ଭ', 0x42: 'ମ', 0x43: 'ଯ', 0x44: 'ର', 0x46: 'ଲ',
+	0x47: 'ଳ', 0x49: 'ଵ', 0x4a: 'ଶ', 0x4b: 'ଷ', 0x4c: 'ସ', 0x4d: 'ହ', 0x4e: '\u0b3c', 0x4f: 'ଽ',
+	0x50: '\u0b3e', 0x51: '\u0b3f', 0x52: '\u0b40', 0x53: '\u0b41', 0x54: '\u0b42', 0x55: '\u0b43', 0x56: '\u0b44', 0x59: '\u0b47',
+	0x5a: '\u0b48', 0x5d: '\u0b4b', 0x5e: '\u0b4c', 0x5f: '\u0b4d', 0x60: '\u0b56', 0x61: 'a', 0x62: 'b', 0x63: 'c',
+	0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g', 0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k',
+	0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's',
+	0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: '\u0b57',
+	0x7c: 'ୠ', 0x7d: 'ୡ', 0x7e: '\u0b62', 0x7f: '\u0b63',
+}
+
+// Oriya single shift table.
+var oriyaSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '।', 0x1a: '॥', 0x1c: '୦', 0x1d: '୧', 0x1e: '୨', 0x1f: '୩', 0x20: '୪', 0x21: '୫',
+	0x22: '୬', 0x23: '୭', 0x24: '୮', 0x25: '୯', 0x26: 'ଡ଼', 0x27: 'ଢ଼', 0x28: '{', 0x29: '}',
+	0x2a: 'ୟ', 0x2b: '୰', 0x2c: 'ୱ', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|',
+	0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H',
+	0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P',
+	0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X',
+	0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+}
+
+// Punjabi locking shift table.
+var punjabiLocking = map[byte]rune{
+	0x00: '\u0a01', 0x01: '\u0a02', 0x02: '\u0a03', 0x03: 'ਅ', 0x04: 'ਆ', 0x05: 'ਇ', 0x06: 'ਈ', 0x07: 'ਉ',
+	0x08: 'ਊ', 0x0a: '\n', 0x0d: '\r', 0x0f: 'ਏ', 0x10: 'ਐ', 0x13: 'ਓ', 0x14: 'ਔ', 0x15: 'ਕ',
+	0x16: 'ਖ', 0x17: 'ਗ', 0x18: 'ਘ', 0x19: 'ਙ', 0x1a: 'ਚ', 0x1c: 'ਛ', 0x1d: 'ਜ', 0x1e: 'ਝ',
+	0x1f: 'ਞ', 0x20: ' ', 0x21: '!', 0x22: 'ਟ', 0x23: 'ਠ', 0x24: 'ਡ', 0x25: 'ਢ', 0x26: 'ਣ',
+	0x27: 'ਤ', 0x28: ')', 0x29: '(', 0x2a: 'ਥ', 0x2b: 'ਦ', 0x2c: ',', 0x2d: 'ਧ', 0x2e: '.',
+	0x2f: 'ਨ', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6',
+	0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';', 0x3d: 'ਪ', 0x3e: 'ਫ', 0x3f: '?',
+	0x40: 'ਬ', 0x41: 'ਭ', 0x42: 'ਮ', 0x43: 'ਯ', 0x44: 'ਰ', 0x46: 'ਲ', 0x47: 'ਲ਼', 0x49: 'ਵ',
+	0x4a: 'ਸ਼', 0x4c: 'ਸ', 0x4d: 'ਹ', 0x4e: '\u0a3c', 0x50: '\u0a3e', 0x51: '\u0a3f', 0x52: '\u0a40', 0x53: '\u0a41',
+	0x54: '\u0a42', 0x59: '\u0a47', 0x5a: '\u0a48', 0x5d: '\u0a4b', 0x5e: '\u0a4c', 0x5f: '\u0a4d', 0x60: '\u0a51', 0x61: 'a',
+	0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g', 0x68: 'h', 0x69: 'i',
+	0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q',
+	0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+	0x7a: 'z', 0x7b: 'ੰ', 0x7c: 'ੱ', 0x7d: 'ੲ', 0x7e: 'ੳ', 0x7f: 'ੴ',
+}
+
+// Punjabi single shift table.
+var punjabiSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '।', 0x1a: '॥', 0x1c: '੦', 0x1d: '੧', 0x1e: '੨', 0x1f: '੩', 0x20: '੪', 0x21: '੫',
+	0x22: '੬', 0x23: '੭', 0x24: '੮', 0x25: '੯', 0x26: 'ਖ਼', 0x27: 'ਗ਼', 0x28: '{', 0x29: '}',
+	0x2a: 'ਜ਼', 0x2b: 'ੜ', 0x2c: 'ਫ਼', 0x2d: 'ੵ', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+	0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G',
+	0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O',
+	0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W',
+	0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+}
+
+// Tamil locking shift table.
+var tamilLocking = map[byte]rune{
+	0x01: '\u0b82', 0x02: '\u0b83', 0x03: 'அ', 0x04: 'ஆ', 0x05: 'இ', 0x06: 'ஈ', 0x07: 'உ', 0x08: 'ஊ',
+	0x0a: '\n', 0x0d: '\r', 0x0e: 'எ', 0x0f: 'ஏ', 0x10: 'ஐ', 0x12: 'ஒ', 0x13: 'ஓ', 0x14: 'ஔ',
+	0x15: 'க', 0x19: 'ங', 0x1a: 'ச', 0x1d: 'ஜ', 0x1f: 'ஞ', 0x20: ' ', 0x21: '!', 0x22: 'ட',
+	0x26: 'ண', 0x27: 'த', 0x28: ')', 0x29: '(', 0x2c: ',', 0x2e: '.', 0x2f: 'ந', 0x30: '0',
+	0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8',
+	0x39: '9', 0x3a: ':', 0x3b: ';', 0x3c: 'ன', 0x3d: 'ப', 0x3f: '?', 0x42: 'ம', 0x43: 'ய',
+	0x44: 'ர', 0x45: 'ற', 0x46: 'ல', 0x47: 'ள', 0x48: 'ழ', 0x49: 'வ', 0x4a: 'ஶ', 0x4b: 'ஷ',
+	0x4c: 'ஸ', 0x4d: 'ஹ', 0x50: '\u0bbe', 0x51: '\u0bbf', 0x52: '\u0bc0', 0x53: '\u0bc1', 0x54: '\u0bc2', 0x58: '\u0bc6',
+	0x59: '\u0bc7', 0x5a: '\u0bc8', 0x5c: '\u0bca', 0x5d: '\u0bcb', 0x5e: '\u0bcc', 0x5f: '\u0bcd', 0x60: 'ௐ', 0x61: 'a',
+	0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g', 0x68: 'h', 0x69: 'i',
+	0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q',
+	0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+	0x7a: 'z', 0x7b: '\u0bd7', 0x7c: '௰', 0x7d: '௱', 0x7e: '௲', 0x7f: '௹',
+}
+
+// Tamil single shift table.
+var tamilSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '।', 0x1a: '॥', 0x1c: '௦', 0x1d: '௧', 0x1e: '௨', 0x1f: '௩', 0x20: '௪', 0x21: '௫',
+	0x22: '௬', 0x23: '௭', 0x24: '௮', 0x25: '௯', 0x26: '௳', 0x27: '௴', 0x28: '{', 0x29: '}',
+	0x2a: '௵', 0x2b: '௶', 0x2c: '௷', 0x2d: '௸', 0x2e: '௺', 0x2f: '\\', 0x3c: '[', 0x3d: '~',
+	0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F',
+	0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N',
+	0x4f: 'O', 0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V',
+	0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+}
+
+// Telugu locking shift table.
+var teluguLocking = map[byte]rune{
+	0x00: '\u0c01', 0x01: '\u0c02', 0x02: '\u0c03', 0x03: 'అ', 0x04: 'ఆ', 0x05: 'ఇ', 0x06: 'ఈ', 0x07: 'ఉ',
+	0x08: 'ఊ', 0x09: 'ఋ', 0x0a: '\n', 0x0b: 'ఌ', 0x0d: '\r', 0x0e: 'ఎ', 0x0f: 'ఏ', 0x10: 'ఐ',
+	0x12: 'ఒ', 0x13: 'ఓ', 0x14: 'ఔ', 0x15: 'క', 0x16: 'ఖ', 0x17: 'గ', 0x18: 'ఘ', 0x19: 'ఙ',
+	0x1a: 'చ', 0x1c: 'ఛ', 0x1d: 'జ', 0x1e: 'ఝ', 0x1f: 'ఞ', 0x20: ' ', 0x21: '!', 0x22: 'ట',
+	0x23: 'ఠ', 0x24: 'డ', 0x25: 'ఢ', 0x26: 'ణ', 0x27: 'త', 0x28: ')', 0x29: '(', 0x2a: 'థ',
+	0x2b: 'ద', 0x2c: ',', 0x2d: 'ధ', 0x2e: '.', 0x2f: 'న', 0x30: '0', 0x31: '1', 0x32: '2',
+	0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':',
+	0x3b: ';', 0x3d: 'ప', 0x3e: 'ఫ', 0x3f: '?', 0x40: 'బ', 0x41: 'భ', 0x42: 'మ', 0x43: 'య',
+	0x44: 'ర', 0x45: 'ఱ', 0x46: 'ల', 0x47: 'ళ', 0x49: 'వ', 0x4a: 'శ', 0x4b: 'ష', 0x4c: 'స',
+	0x4d: 'హ', 0x4f: 'ఽ', 0x50: '\u0c3e', 0x51: '\u0c3f', 0x52: '\u0c40', 0x53: '\u0c41', 0x54: '\u0c42', 0x55: '\u0c43',
+	0x56: '\u0c44', 0x58: '\u0c46', 0x59: '\u0c47', 0x5a: '\u0c48', 0x5c: '\u0c4a', 0x5d: '\u0c4b', 0x5e: '\u0c4c', 0x5f: '\u0c4d',
+	0x60: '\u0c55', 0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+	0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o',
+	0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w',
+	0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: '\u0c56', 0x7c: 'ౠ', 0x7d: 'ౡ', 0x7e: '\u0c62', 0x7f: '\u0c63',
+}
+
+// Telugu single shift table.
+var teluguSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x1c: '౦', 0x1d: '౧', 0x1e: '౨', 0x1f: '౩', 0x20: '౪', 0x21: '౫', 0x22: '౬', 0x23: '౭',
+	0x24: '౮', 0x25: '౯', 0x26: 'ౘ', 0x27: 'ౙ', 0x28: '{', 0x29: '}', 0x2a: '౸', 0x2b: '౹',
+	0x2c: '౺', 0x2d: '౻', 0x2e: '౼', 0x2f: '\\', 0x30: '౽', 0x31: '౾', 0x32: '౿', 0x3c: '[',
+	0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E',
+	0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M',
+	0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U',
+	0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+}
+
+// Urdu locking shift table.
+var urduLocking = map[byte]rune{
+	0x00: 'ا', 0x01: 'آ', 0x02: 'ب', 0x03: 'ٻ', 0x04: 'ڀ', 0x05: 'پ', 0x06: 'ڦ', 0x07: 'ت',
+	0x08: 'ۂ', 0x09: 'ٿ', 0x0a: '\n', 0x0b: 'ٹ', 0x0c: 'ٽ', 0x0d: '\r', 0x0e: 'ٺ', 0x0f: 'ټ',
+	0x10: 'ث', 0x11: 'ج', 0x12: 'ځ', 0x13: 'ڄ', 0x14: 'ڃ', 0x15: 'څ', 0x16: 'چ', 0x17: 'ڇ',
+	0x18: 'ح', 0x19: 'خ', 0x1a: 'د', 0x1c: 'ڌ', 0x1d: 'ڈ', 0x1e: 'ډ', 0x1f: 'ڊ', 0x20: ' ',
+	0x21: '!', 0x22: 'ڏ', 0x23: 'ڍ', 0x24: 'ذ', 0x25: 'ر', 0x26: 'ڑ', 0x27: 'ړ', 0x28: ')',
+	0x29: '(', 0x2a: 'ڙ', 0x2b: 'ز', 0x2c: ',', 0x2d: 'ږ', 0x2e: '.', 0x2f: 'ژ', 0x30: '0',
+	0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8',
+	0x39: '9', 0x3a: ':', 0x3b: ';', 0x3c: 'ښ', 0x3d: 'س', 0x3e: 'ش', 0x3f: '?', 0x40: 'ص',
+	0x41: 'ض', 0x42: 'ط', 0x43: 'ظ', 0x44: 'ع', 0x45: 'ف', 0x46: 'ق', 0x47: 'ک', 0x48: 'ڪ',
+	0x49: 'ګ', 0x4a: 'گ', 0x4b: 'ڳ', 0x4c: 'ڱ', 0x4d: 'ل', 0x4e: 'م', 0x4f: 'ن', 0x50: 'ں',
+	0x51: 'ڻ', 0x52: 'ڼ', 0x53: 'و', 0x54: 'ۄ', 0x55: 'ە', 0x56: 'ہ', 0x57: 'ھ', 0x58: 'ء',
+	0x59: 'ی', 0x5a: 'ې', 0x5b: 'ے', 0x5c: '\u064d', 0x5d: '\u0650', 0x5e: '\u064f', 0x5f: '\u0657', 0x60: '\u0654',
+	0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g', 0x68: 'h',
+	0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p',
+	0x71: 'q', 0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x',
+	0x79: 'y', 0x7a: 'z', 0x7b: '\u0655', 0x7c: '\u0651', 0x7d: '\u0653', 0x7e: '\u0656', 0x7f: '\u0670',
+}
+
+// Urdu single shift table.
+var urduSingle = map[byte]rune{
+	0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"', 0x06: '¤', 0x07: '%',
+	0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*', 0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<',
+	0x11: '=', 0x12: '>', 0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+	0x19: '؀', 0x1a: '؁', 0x1c: '۰', 0x1d: '۱', 0x1e: '۲', 0x1f: '۳', 0x20: '۴', 0x21: '۵',
+	0x22: '۶', 0x23: '۷', 0x24: '۸', 0x25: '۹', 0x26: '،', 0x27: '؍', 0x28: '{', 0x29: '}',
+	0x2a: '؎', 0x2b: '؏', 0x2c: 'ؐ', 0x2d: 'ؑ', 0x2e: 'ؒ', 0x2f: '\\', 0x30: 'ؓ', 0x31: 'ؔ',
+	0x32: '؛', 0x33: '؟', 0x34: 'ـ', 0x35: '\u0652', 0x36: '\u0658', 0x37: '٫', 0x38: '٬', 0x39: 'ٲ',
+	0x3a: 'ٳ', 0x3b: 'ۍ', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x3f: '۔', 0x40: '|', 0x41: 'A',
+	0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I',
+	0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+	0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y',
+	0x5a: 'Z', 0x65: '€',
+}
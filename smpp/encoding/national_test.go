@@ -0,0 +1,74 @@
+package encoding
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+var nationalTests = []struct {
+	Locking Language
+	Single  Language
+	Text    string
+	Buff    []byte
+}{
+	{Turkish, Turkish, "Işık €", []byte{0x49, 0x1d, 0x07, 0x6b, 0x20, 0x04}},
+	{DefaultLanguage, Turkish, "Işık", []byte{0x49, 0x1b, 0x73, 0x1b, 0x69, 0x6b}},
+	{DefaultLanguage, Spanish, "Canción", []byte{0x43, 0x61, 0x6e, 0x63, 0x69, 0x1b, 0x6f, 0x6e}},
+	{Portuguese, Portuguese, "Ação", []byte{0x41, 0x09, 0x7b, 0x6f}},
+	{Hindi, Hindi, "नमस्ते १", []byte{0x2f, 0x42, 0x4c, 0x5f, 0x27, 0x59, 0x20, 0x1b, 0x1d}},
+	// languages without locking shift table use the default alphabet.
+	{Spanish, DefaultLanguage, "{@}", []byte{0x1b, 0x28, 0x00, 0x1b, 0x29}},
+}
+
+func TestNationalEncoder(t *testing.T) {
+	for index, row := range nationalTests {
+		encoder := GSM7National(false, row.Locking, row.Single).NewEncoder()
+		es, _, err := transform.Bytes(encoder, []byte(row.Text))
+		if err != nil {
+			t.Fatalf("%2d: unexpected error: '%s'", index, err.Error())
+		}
+		if !reflect.DeepEqual(es, row.Buff) {
+			t.Fatalf("%2d: actual did not equal expected.\nactual: %s\nexpect: %s", index, hex.EncodeToString(es), hex.EncodeToString(row.Buff))
+		}
+	}
+}
+
+func TestNationalDecoder(t *testing.T) {
+	for index, row := range nationalTests {
+		decoder := GSM7National(false, row.Locking, row.Single).NewDecoder()
+		es, _, err := transform.Bytes(decoder, row.Buff)
+		if err != nil {
+			t.Fatalf("%2d: unexpected error: '%s'", index, err.Error())
+		}
+		if string(es) != row.Text {
+			t.Fatalf("%2d: actual did not equal expected.\nactual: %s\nexpect: %s", index, es, row.Text)
+		}
+	}
+}
+
+func TestNationalInvalidCharacter(t *testing.T) {
+	encoder := GSM7National(false, DefaultLanguage, Turkish).NewEncoder()
+	if _, _, err := transform.Bytes(encoder, []byte("ã")); err != ErrInvalidCharacter {
+		t.Fatalf("unexpected error: want %v, have %v", ErrInvalidCharacter, err)
+	}
+	if invalid := ValidateGSM7NationalString("Işık ã", Turkish, Turkish); !reflect.DeepEqual(invalid, []rune{'ã'}) {
+		t.Fatalf("unexpected invalid characters: %q", invalid)
+	}
+	if n := GSM7NationalRuneLen('ş', DefaultLanguage, Turkish); n != 2 {
+		t.Fatalf("unexpected length: want 2, have %d", n)
+	}
+}
+
+func TestNationalEncodingString(t *testing.T) {
+	want := "GSM 7-bit (Unpacked) Turkish locking shift Turkish single shift"
+	if have := fmt.Sprint(GSM7National(false, Turkish, Turkish)); have != want {
+		t.Fatalf("expected '%s' but got '%s'", want, have)
+	}
+	if !Spanish.HasSingleShift() || Spanish.HasLockingShift() {
+		t.Fatal("unexpected shift tables for Spanish")
+	}
+}
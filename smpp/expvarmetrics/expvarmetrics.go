@@ -0,0 +1,113 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package expvarmetrics implements smpp.Metrics with expvar variables.
+package expvarmetrics
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+)
+
+// Metrics implements smpp.Metrics, keeping the measurements in an
+// expvar.Map with the following variables:
+//
+//	pdus_sent         PDUs written, by PDU name.
+//	pdus_received     PDUs read, by PDU name.
+//	responses         Requests that got a response, by PDU name.
+//	latency_us        Sum of their round-trip times in microseconds.
+//	timeouts          Requests that timed out, by PDU name.
+//	window_inflight   Requests waiting for a response.
+//	window_queued     Callers waiting for a free slot in the window.
+//	status            Connection status changes, by status.
+//	reconnects        Connections after the first one.
+//	bind_failures     Failed bind attempts.
+type Metrics struct {
+	*expvar.Map
+
+	sent, received, responses, latency, timeouts, status *expvar.Map
+	inflight, queued, reconnects, bindFailures           *expvar.Int
+
+	connected int32
+}
+
+// New returns a Metrics published in expvar with the given name. Like
+// expvar.Publish, it panics if the name is already in use.
+func New(name string) *Metrics {
+	m := newMetrics()
+	expvar.Publish(name, m.Map)
+	return m
+}
+
+// newMetrics returns an unpublished Metrics.
+func newMetrics() *Metrics {
+	m := &Metrics{
+		Map:          new(expvar.Map).Init(),
+		sent:         new(expvar.Map).Init(),
+		received:     new(expvar.Map).Init(),
+		responses:    new(expvar.Map).Init(),
+		latency:      new(expvar.Map).Init(),
+		timeouts:     new(expvar.Map).Init(),
+		status:       new(expvar.Map).Init(),
+		inflight:     new(expvar.Int),
+		queued:       new(expvar.Int),
+		reconnects:   new(expvar.Int),
+		bindFailures: new(expvar.Int),
+	}
+	m.Set("pdus_sent", m.sent)
+	m.Set("pdus_received", m.received)
+	m.Set("responses", m.responses)
+	m.Set("latency_us", m.latency)
+	m.Set("timeouts", m.timeouts)
+	m.Set("window_inflight", m.inflight)
+	m.Set("window_queued", m.queued)
+	m.Set("status", m.status)
+	m.Set("reconnects", m.reconnects)
+	m.Set("bind_failures", m.bindFailures)
+	return m
+}
+
+// PDUSent implements the smpp.Metrics interface.
+func (m *Metrics) PDUSent(id pdu.ID) {
+	m.sent.Add(id.String(), 1)
+}
+
+// PDUReceived implements the smpp.Metrics interface.
+func (m *Metrics) PDUReceived(id pdu.ID) {
+	m.received.Add(id.String(), 1)
+}
+
+// Latency implements the smpp.Metrics interface.
+func (m *Metrics) Latency(id pdu.ID, d time.Duration) {
+	m.responses.Add(id.String(), 1)
+	m.latency.Add(id.String(), int64(d/time.Microsecond))
+}
+
+// Timeout implements the smpp.Metrics interface.
+func (m *Metrics) Timeout(id pdu.ID) {
+	m.timeouts.Add(id.String(), 1)
+}
+
+// Window implements the smpp.Metrics interface.
+func (m *Metrics) Window(s smpp.WindowStatus) {
+	m.inflight.Set(int64(s.Inflight))
+	m.queued.Set(int64(s.Queued))
+}
+
+// ConnStatus implements the smpp.Metrics interface.
+func (m *Metrics) ConnStatus(s smpp.ConnStatusID) {
+	m.status.Add(s.String(), 1)
+	switch s {
+	case smpp.Connected:
+		if !atomic.CompareAndSwapInt32(&m.connected, 0, 1) {
+			m.reconnects.Add(1)
+		}
+	case smpp.BindFailed:
+		m.bindFailures.Add(1)
+	}
+}
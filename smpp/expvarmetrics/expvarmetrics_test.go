@@ -0,0 +1,52 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package expvarmetrics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+)
+
+func TestMetrics(t *testing.T) {
+	m := newMetrics()
+	var _ smpp.Metrics = m
+	m.PDUSent(pdu.SubmitSMID)
+	m.PDUSent(pdu.SubmitSMID)
+	m.PDUReceived(pdu.SubmitSMRespID)
+	m.Latency(pdu.SubmitSMID, 1500*time.Microsecond)
+	m.Timeout(pdu.SubmitSMID)
+	m.Window(smpp.WindowStatus{Size: 10, Inflight: 3, Queued: 1})
+	m.ConnStatus(smpp.Connected)
+	m.ConnStatus(smpp.Disconnected)
+	m.ConnStatus(smpp.BindFailed)
+	m.ConnStatus(smpp.Connected)
+	var v struct {
+		Sent       map[string]int `json:"pdus_sent"`
+		Received   map[string]int `json:"pdus_received"`
+		Latency    map[string]int `json:"latency_us"`
+		Timeouts   map[string]int `json:"timeouts"`
+		Inflight   int            `json:"window_inflight"`
+		Queued     int            `json:"window_queued"`
+		Reconnects int            `json:"reconnects"`
+		Failures   int            `json:"bind_failures"`
+	}
+	if err := json.Unmarshal([]byte(m.String()), &v); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case v.Sent["SubmitSM"] != 2, v.Received["SubmitSMResp"] != 1:
+		t.Fatalf("unexpected PDU counts: %s", m)
+	case v.Latency["SubmitSM"] != 1500, v.Timeouts["SubmitSM"] != 1:
+		t.Fatalf("unexpected latency: %s", m)
+	case v.Inflight != 3, v.Queued != 1:
+		t.Fatalf("unexpected window: %s", m)
+	case v.Reconnects != 1, v.Failures != 1:
+		t.Fatalf("unexpected connection counts: %s", m)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+)
+
+// FlowControl configures the adaptive flow control of a Transmitter
+// or Transceiver.
+//
+// Requests rejected by the SMSC with StatusThrottled or StatusMsgQFull
+// are retried after a back-off, during which no other requests are
+// sent. SubmitAsync applies the back-off but does not retry, and with
+// a RetryPolicy the retries are left to the policy.
+//
+// The effective rate and window are halved, at most once per Backoff,
+// when requests are throttled or the SMSC reports a congestion_state
+// TLV of 90 or above (SMPP 5.0). They grow back as requests succeed
+// while the congestion_state, if any, stays below 80.
+//
+// The rate is that of the RateLimiter of the client if it can be
+// adjusted, like *rate.Limiter of package golang.org/x/time/rate: its
+// limit is scaled by the flow control, starting from Rate if set or
+// else from the limit it had on Bind. Otherwise, if Rate is set, the
+// flow control paces requests itself and the RateLimiter is not used.
+// A RateLimiter that cannot be adjusted keeps its fixed pace when Rate
+// is not set, and only the window is reduced.
+type FlowControl struct {
+	Rate       float64       // Max requests per second, see above.
+	MinRate    float64       // Floor of the effective rate, default Rate/16.
+	MaxRetries int           // Retries of throttled requests without RetryPolicy, default 3, none if negative.
+	Backoff    time.Duration // Back-off of the first retry, doubled on every retry, default 1s.
+	MaxBackoff time.Duration // Max back-off, default 30s.
+}
+
+// FlowStatus reports the state of the flow control of a Transmitter
+// or Transceiver.
+type FlowStatus struct {
+	Rate       float64 // Effective rate in requests per second, zero if unlimited.
+	Window     int     // Effective window size, zero if unlimited.
+	Congestion int     // Last congestion_state reported by the SMSC, -1 if none.
+	Throttled  uint64  // Number of requests rejected with a throttling status.
+}
+
+// Congestion states of the congestion_state TLV, from 0 (idle) to 100
+// (congested). The SMSC operates best between 80 and 89.
+const (
+	congestionOptimum = 80
+	congestionHigh    = 90
+)
+
+// flow implements FlowControl for a client connection.
+type flow struct {
+	cfg FlowControl
+	w   *window
+	lim adjustableLimiter // scaled instead of pacing requests, if any.
+
+	mu         sync.Mutex
+	factor     float64   // fraction of the configured rate and window in use.
+	min        float64   // floor of factor.
+	next       time.Time // earliest time of the next request.
+	pause      time.Time // no requests until then, after throttling.
+	decreased  time.Time // time of the last reduction of factor.
+	congestion int
+	throttled  uint64
+}
+
+// adjustableLimiter is a RateLimiter whose limit can be changed, such
+// as *rate.Limiter.
+type adjustableLimiter interface {
+	RateLimiter
+	Limit() rate.Limit
+	SetLimit(rate.Limit)
+}
+
+// newFlow returns the flow control of a client with the given window
+// and RateLimiter, which is scaled if it is an adjustableLimiter.
+func newFlow(cfg *FlowControl, w *window, rl RateLimiter) *flow {
+	f := &flow{cfg: *cfg, w: w, factor: 1, min: 1.0 / 16, congestion: -1}
+	if al, ok := rl.(adjustableLimiter); ok {
+		if f.cfg.Rate == 0 && al.Limit() != rate.Inf {
+			f.cfg.Rate = float64(al.Limit())
+		}
+		if f.cfg.Rate > 0 {
+			f.lim = al
+			al.SetLimit(rate.Limit(f.cfg.Rate))
+		}
+	}
+	if f.cfg.MaxRetries == 0 {
+		f.cfg.MaxRetries = 3
+	}
+	if f.cfg.Backoff == 0 {
+		f.cfg.Backoff = time.Second
+	}
+	if f.cfg.MaxBackoff == 0 {
+		f.cfg.MaxBackoff = 30 * time.Second
+	}
+	if f.cfg.Rate > 0 && f.cfg.MinRate > 0 {
+		f.min = math.Min(f.cfg.MinRate/f.cfg.Rate, 1)
+	}
+	return f
+}
+
+// wait blocks until the back-off is over and the effective rate
+// permits another request, or ctx is done.
+func (f *flow) wait(ctx context.Context) error {
+	f.mu.Lock()
+	now := time.Now()
+	at := now
+	if f.pause.After(at) {
+		at = f.pause
+	}
+	if f.cfg.Rate > 0 && f.lim == nil {
+		if f.next.After(at) {
+			at = f.next
+		}
+		f.next = at.Add(time.Duration(float64(time.Second) / (f.cfg.Rate * f.factor)))
+	}
+	f.mu.Unlock()
+	if !at.After(now) {
+		return nil
+	}
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observe adjusts the effective rate and window to the response p,
+// and reports whether the request was throttled and may be retried
+// after the given number of retries. The back-off of the retry is
+// applied to all requests.
+func (f *flow) observe(p pdu.Body, retries int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if c, ok := p.TLVFields().Uint8(pdutlv.TagCongestionState); ok {
+		f.congestion = int(c)
+	}
+	switch s := p.Header().Status; {
+	case s == pdu.StatusThrottled || s == pdu.StatusMsgQFull:
+		f.throttled++
+		f.decrease(now)
+		d := f.cfg.Backoff << uint(retries)
+		if d > f.cfg.MaxBackoff || d <= 0 {
+			d = f.cfg.MaxBackoff
+		}
+		if pause := now.Add(d); pause.After(f.pause) {
+			f.pause = pause
+		}
+		return retries < f.cfg.MaxRetries
+	case f.congestion >= congestionHigh:
+		f.decrease(now)
+	case f.congestion < congestionOptimum && s == pdu.StatusOK:
+		f.increase()
+	}
+	return false
+}
+
+// decrease halves the effective rate and window, at most once per
+// back-off interval so that a burst of responses counts once.
+func (f *flow) decrease(now time.Time) {
+	if now.Sub(f.decreased) < f.cfg.Backoff {
+		return
+	}
+	f.decreased = now
+	f.factor = math.Max(f.factor/2, f.min)
+	f.resize()
+}
+
+// increase grows the effective rate and window back by a small step,
+// recovering the configured values after some 100 successes.
+func (f *flow) increase() {
+	if f.factor >= 1 {
+		return
+	}
+	f.factor = math.Min(f.factor+0.01, 1)
+	f.resize()
+}
+
+// resize sets the effective window size, and the limit of the
+// RateLimiter if adjusted, from factor.
+func (f *flow) resize() {
+	if f.lim != nil {
+		f.lim.SetLimit(rate.Limit(f.cfg.Rate * f.factor))
+	}
+	if f.w.size > 0 {
+		f.w.setLimit(uint(math.Ceil(float64(f.w.size) * f.factor)))
+	}
+}
+
+// status returns the current state of the flow control.
+func (f *flow) status() FlowStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := FlowStatus{
+		Rate:       f.cfg.Rate * f.factor,
+		Congestion: f.congestion,
+		Throttled:  f.throttled,
+	}
+	if f.w.size > 0 {
+		s.Window = int(math.Ceil(float64(f.w.size) * f.factor))
+	}
+	return s
+}
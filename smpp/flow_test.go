@@ -0,0 +1,188 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+)
+
+func TestFlow(t *testing.T) {
+	w := newWindow(8, true)
+	f := newFlow(&FlowControl{Rate: 100, MinRate: 20, Backoff: time.Millisecond}, w, nil)
+	resp := func(s pdu.Status, congestion int) pdu.Body {
+		p := pdu.NewSubmitSMResp()
+		p.Header().Status = s
+		if congestion >= 0 {
+			p.TLVFields().Set(pdutlv.TagCongestionState, uint8(congestion))
+		}
+		return p
+	}
+	want := FlowStatus{Rate: 100, Window: 8, Congestion: -1}
+	if s := f.status(); s != want {
+		t.Fatalf("unexpected status: want %#v, have %#v", want, s)
+	}
+	if !f.observe(resp(pdu.StatusThrottled, -1), 0) {
+		t.Fatal("throttled request not retried")
+	}
+	want = FlowStatus{Rate: 50, Window: 4, Congestion: -1, Throttled: 1}
+	if s := f.status(); s != want {
+		t.Fatalf("unexpected status: want %#v, have %#v", want, s)
+	}
+	if f.observe(resp(pdu.StatusMsgQFull, -1), 3) {
+		t.Fatal("throttled request retried more than MaxRetries")
+	}
+	// Decreases within the back-off interval count once.
+	if s := f.status(); s.Rate != 50 || s.Throttled != 2 {
+		t.Fatalf("unexpected status: %#v", s)
+	}
+	time.Sleep(2 * time.Millisecond)
+	f.observe(resp(pdu.StatusOK, 95), 0)
+	want = FlowStatus{Rate: 25, Window: 2, Congestion: 95, Throttled: 2}
+	if s := f.status(); s != want {
+		t.Fatalf("unexpected status: want %#v, have %#v", want, s)
+	}
+	time.Sleep(2 * time.Millisecond)
+	f.observe(resp(pdu.StatusOK, 100), 0)
+	if s := f.status(); s.Rate != 20 || s.Window != 2 {
+		t.Fatalf("unexpected status at MinRate: %#v", s)
+	}
+	// Optimum load holds the rate, lower load recovers it.
+	f.observe(resp(pdu.StatusOK, 85), 0)
+	if s := f.status(); s.Rate != 20 {
+		t.Fatalf("unexpected rate at optimum load: %v", s.Rate)
+	}
+	for i := 0; i < 100; i++ {
+		f.observe(resp(pdu.StatusOK, 10), 0)
+	}
+	if s := f.status(); s.Rate != 100 || s.Window != 8 {
+		t.Fatalf("unexpected status after recovery: %#v", s)
+	}
+}
+
+// fixedLimiter is a RateLimiter that cannot be adjusted.
+type fixedLimiter struct{}
+
+func (fixedLimiter) Wait(ctx context.Context) error { return nil }
+
+func TestFlowRateLimiter(t *testing.T) {
+	lim := rate.NewLimiter(100, 1)
+	f := newFlow(&FlowControl{Backoff: time.Millisecond}, newWindow(0, false), lim)
+	if f.lim == nil {
+		t.Fatal("rate limiter not adjusted")
+	}
+	throttled := pdu.NewSubmitSMResp()
+	throttled.Header().Status = pdu.StatusThrottled
+	f.observe(throttled, 0)
+	if l := lim.Limit(); l != 50 {
+		t.Fatalf("unexpected limit after throttling: %v", l)
+	}
+	if s := f.status(); s.Rate != 50 {
+		t.Fatalf("unexpected rate: %v", s.Rate)
+	}
+	for i := 0; i < 100; i++ {
+		f.observe(pdu.NewSubmitSMResp(), 0)
+	}
+	if l := lim.Limit(); l != 100 {
+		t.Fatalf("unexpected limit after recovery: %v", l)
+	}
+	// A limiter that cannot be adjusted is replaced by Rate.
+	c := &client{
+		FlowControl: &FlowControl{Rate: 10},
+		RateLimiter: fixedLimiter{},
+	}
+	c.init()
+	if c.RateLimiter != nil {
+		t.Fatal("rate limiter not replaced by FlowControl.Rate")
+	}
+}
+
+func TestFlowWait(t *testing.T) {
+	f := newFlow(&FlowControl{Rate: 100}, newWindow(0, false), nil)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := f.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("requests sent faster than the rate: %s", d)
+	}
+	f.observe(pdu.NewSubmitSMResp(), 0)
+	f.pause = time.Now().Add(time.Hour)
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := f.wait(cctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: want %v, have %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestSubmitThrottled(t *testing.T) {
+	var n int32
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		if atomic.AddInt32(&n, 1) <= 2 {
+			r.Header().Status = pdu.StatusThrottled
+		} else {
+			r.Fields().Set(pdufield.MessageID, "foobar")
+		}
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		FlowControl: &FlowControl{Backoff: 10 * time.Millisecond},
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	start := time.Now()
+	sm, err := tx.Submit(&ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sm.RespID() != "foobar" {
+		t.Fatalf("unexpected msgid: want foobar, have %q", sm.RespID())
+	}
+	// Back-off of 10ms, then 20ms.
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Fatalf("throttled request retried without back-off: %s", d)
+	}
+	if s := tx.FlowStatus(); s.Throttled != 2 {
+		t.Fatalf("unexpected throttled count: want 2, have %d", s.Throttled)
+	}
+	// Without retries the status is returned.
+	atomic.StoreInt32(&n, 0)
+	tx.cl.flow.cfg.MaxRetries = -1
+	_, err = tx.Submit(&ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")})
+	if err != pdu.StatusThrottled {
+		t.Fatalf("unexpected error: want %v, have %v", pdu.StatusThrottled, err)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+)
+
+// Metrics receives measurements of the client connection of a
+// Transmitter, Transceiver or Receiver. See packages expvarmetrics
+// and prommetrics for implementations.
+//
+// Methods are called synchronously from the client, and must be safe
+// for concurrent use and return quickly.
+type Metrics interface {
+	// PDUSent is called for every PDU written to the connection,
+	// and PDUReceived for every PDU read from it.
+	PDUSent(id pdu.ID)
+	PDUReceived(id pdu.ID)
+
+	// Latency is called with the round-trip time of requests of
+	// the given ID, e.g. SubmitSMID, when their response arrives,
+	// and Timeout when it does not arrive within RespTimeout.
+	Latency(id pdu.ID, d time.Duration)
+	Timeout(id pdu.ID)
+
+	// Window is called with the occupancy of the window every
+	// time a request takes or releases a slot.
+	Window(s WindowStatus)
+
+	// ConnStatus is called on every status change of the
+	// connection, including the ones dropped from the status
+	// channel because it was not drained.
+	ConnStatus(s ConnStatusID)
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+)
+
+type testMetrics struct {
+	mu        sync.Mutex
+	sent      map[pdu.ID]int
+	received  map[pdu.ID]int
+	latencies int
+	timeouts  int
+	windows   int
+	status    []ConnStatusID
+}
+
+func (m *testMetrics) PDUSent(id pdu.ID) {
+	m.mu.Lock()
+	m.sent[id]++
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) PDUReceived(id pdu.ID) {
+	m.mu.Lock()
+	m.received[id]++
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) Latency(id pdu.ID, d time.Duration) {
+	m.mu.Lock()
+	m.latencies++
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) Timeout(id pdu.ID) {
+	m.mu.Lock()
+	m.timeouts++
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) Window(s WindowStatus) {
+	m.mu.Lock()
+	m.windows++
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) ConnStatus(s ConnStatusID) {
+	m.mu.Lock()
+	m.status = append(m.status, s)
+	m.mu.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID == pdu.QuerySMID {
+			return // times out
+		}
+		smpptest.EchoHandler(c, p)
+	}
+	s.Start()
+	defer s.Close()
+	m := &testMetrics{sent: make(map[pdu.ID]int), received: make(map[pdu.ID]int)}
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		RespTimeout: 50 * time.Millisecond,
+		Metrics:     m,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	// The echo server replies with the request itself.
+	tx.Submit(&ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")})
+	if _, err := tx.QuerySM("root", "13", 0, 0); err != ErrTimeout {
+		t.Fatalf("unexpected error: want %v, have %v", ErrTimeout, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case m.sent[pdu.BindTransmitterID] != 1, m.received[pdu.BindTransmitterRespID] != 1:
+		t.Fatalf("unexpected bind PDUs: %v, %v", m.sent, m.received)
+	case m.sent[pdu.SubmitSMID] != 1, m.received[pdu.SubmitSMID] != 1:
+		t.Fatalf("unexpected submit PDUs: %v, %v", m.sent, m.received)
+	case m.latencies != 1, m.timeouts != 1, m.windows != 4:
+		t.Fatalf("unexpected measurements: %d latencies, %d timeouts, %d windows",
+			m.latencies, m.timeouts, m.windows)
+	case len(m.status) != 1 || m.status[0] != Connected:
+		t.Fatalf("unexpected status changes: %v", m.status)
+	}
+}
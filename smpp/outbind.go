@@ -0,0 +1,164 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+)
+
+// ErrOutbindAuth is returned when an SMSC sends an Outbind PDU with
+// a system_id or password different from the ones configured in the
+// OutbindReceiver.
+var ErrOutbindAuth = errors.New("outbind authentication failed")
+
+// OutbindReceiver implements an SMPP receiver for SMSCs that initiate
+// the connection with the outbind operation.
+//
+// It listens on Receiver.Addr and waits for the SMSC to connect and
+// send an Outbind PDU. Once the outbind is validated, it binds as a
+// receiver over the same connection using Receiver.User and
+// Receiver.Passwd, and from then on behaves like a Receiver. When the
+// connection drops, it waits for the SMSC to connect again.
+//
+// If Receiver.TLS is set, it is used as the server side configuration
+// for accepted connections.
+//
+// Receiver.BindInterval is the time between accepting connections
+// after one fails or drops, default 100ms. There is no back-off.
+type OutbindReceiver struct {
+	OutbindSystemID string        // Expected outbind system_id, optional.
+	OutbindPasswd   string        // Expected outbind password, optional.
+	OutbindTimeout  time.Duration // Time to wait for outbind, default 10s.
+	Listener        net.Listener  // Listener to accept from, optional.
+
+	Receiver
+
+	lmu    sync.Mutex
+	closed bool
+}
+
+// Bind starts listening for the SMSC and returns a channel that is
+// triggered every time the connection status changes.
+//
+// Bind implements the ClientConn interface.
+func (r *OutbindReceiver) Bind() <-chan ConnStatus {
+	if r.BindInterval == 0 {
+		r.BindInterval = 100 * time.Millisecond
+	}
+	return r.bind(r.accept)
+}
+
+// accept waits for the SMSC to connect and send a valid Outbind PDU.
+func (r *OutbindReceiver) accept() (Conn, error) {
+	l, err := r.listener()
+	if err != nil {
+		return nil, err
+	}
+	fd, err := l.Accept()
+	if err != nil {
+		return nil, err
+	}
+	timeout := r.OutbindTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	// The deadline covers the TLS handshake and the outbind.
+	fd.SetDeadline(time.Now().Add(timeout))
+	if r.TLS != nil {
+		tc := tls.Server(fd, r.TLS)
+		if err = tc.Handshake(); err != nil {
+			fd.Close()
+			return nil, err
+		}
+		fd = tc
+	}
+	c := newConn(fd)
+	p, err := c.Read()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	fd.SetDeadline(time.Time{})
+	if p.Header().ID != pdu.OutbindID {
+		c.Close()
+		return nil, fmt.Errorf("unexpected PDU, want Outbind: %s",
+			p.Header().ID)
+	}
+	f := p.Fields()
+	if !fieldEquals(f, pdufield.SystemID, r.OutbindSystemID) ||
+		!fieldEquals(f, pdufield.Password, r.OutbindPasswd) {
+		c.Close()
+		return nil, ErrOutbindAuth
+	}
+	return c, nil
+}
+
+// listener returns the configured Listener, or starts listening
+// on Receiver.Addr on first use.
+func (r *OutbindReceiver) listener() (net.Listener, error) {
+	r.lmu.Lock()
+	defer r.lmu.Unlock()
+	if r.closed {
+		return nil, ErrNotConnected
+	}
+	if r.Listener != nil {
+		return r.Listener, nil
+	}
+	addr := r.Addr
+	if addr == "" {
+		addr = ":2775"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	r.Listener = l
+	return l, nil
+}
+
+// fieldEquals returns true if want is empty or field n of f matches it.
+func fieldEquals(f pdufield.Map, n pdufield.Name, want string) bool {
+	if want == "" {
+		return true
+	}
+	v := f[n]
+	return v != nil && v.String() == want
+}
+
+// Shutdown stops listening and gracefully terminates the current
+// connection: it sends Unbind and waits for UnbindResp before closing
+// the connection, returning ctx.Err() if ctx is done first.
+func (r *OutbindReceiver) Shutdown(ctx context.Context) error {
+	r.lmu.Lock()
+	r.closed = true
+	if r.Listener != nil {
+		r.Listener.Close()
+	}
+	r.lmu.Unlock()
+	return r.Receiver.Shutdown(ctx)
+}
+
+// Close stops listening and terminates the current connection.
+//
+// Close implements the ClientConn interface.
+func (r *OutbindReceiver) Close() error {
+	err := r.Receiver.Close()
+	r.lmu.Lock()
+	defer r.lmu.Unlock()
+	r.closed = true
+	if r.Listener != nil {
+		r.Listener.Close()
+	}
+	return err
+}
@@ -0,0 +1,186 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+)
+
+// outbind connects to addr as an SMSC and sends an Outbind PDU.
+func outbind(t *testing.T, addr, user, passwd string) *conn {
+	fd, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConn(fd)
+	p := pdu.NewOutbind()
+	p.Fields().Set(pdufield.SystemID, user)
+	p.Fields().Set(pdufield.Password, passwd)
+	if err = c.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestOutbindReceiver(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := make(chan pdu.Body, 1)
+	r := &OutbindReceiver{
+		OutbindSystemID: "smsc",
+		OutbindPasswd:   "secret",
+		Listener:        l,
+		Receiver: Receiver{
+			User:    "client",
+			Passwd:  "passwd",
+			Handler: func(p pdu.Body) { rc <- p },
+		},
+	}
+	defer r.Close()
+	status := r.Bind()
+	c := outbind(t, l.Addr().String(), "smsc", "secret")
+	defer c.Close()
+	p, err := c.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Header().ID != pdu.BindReceiverID {
+		t.Fatalf("unexpected PDU: want BindReceiver, have %s", p.Header().ID)
+	}
+	if id := p.Fields()[pdufield.SystemID]; id == nil || id.String() != "client" {
+		t.Fatalf("unexpected system_id: %#v", id)
+	}
+	resp := pdu.NewBindReceiverResp()
+	resp.Header().Seq = p.Header().Seq
+	if err = c.Write(resp); err != nil {
+		t.Fatal(err)
+	}
+	if s := <-status; s.Status() != Connected {
+		t.Fatalf("unexpected status: want Connected, have %s (%v)",
+			s.Status(), s.Error())
+	}
+	dlr := pdu.NewDeliverSM()
+	dlr.Fields().Set(pdufield.ShortMessage, "Lorem ipsum")
+	if err = c.Write(dlr); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-rc:
+		if m.Header().ID != pdu.DeliverSMID {
+			t.Fatalf("unexpected PDU: %s", m.Header().ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for deliver_sm")
+	}
+}
+
+func TestOutbindReceiverAuth(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &OutbindReceiver{
+		OutbindSystemID: "smsc",
+		OutbindPasswd:   "secret",
+		Listener:        l,
+	}
+	defer r.Close()
+	status := r.Bind()
+	c := outbind(t, l.Addr().String(), "smsc", "wrong")
+	defer c.Close()
+	s := <-status
+	if s.Status() != ConnectionFailed || s.Error() != ErrOutbindAuth {
+		t.Fatalf("unexpected status: want %s (%v), have %s (%v)",
+			ConnectionFailed, ErrOutbindAuth, s.Status(), s.Error())
+	}
+	// The next connection is accepted without a back-off.
+	c = outbind(t, l.Addr().String(), "smsc", "secret")
+	defer c.Close()
+	c.rwc.SetReadDeadline(time.Now().Add(time.Second))
+	p, err := c.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Header().ID != pdu.BindReceiverID {
+		t.Fatalf("unexpected PDU: want BindReceiver, have %s", p.Header().ID)
+	}
+}
+
+func TestOutbindReceiverShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &OutbindReceiver{Listener: l}
+	status := r.Bind()
+	c := outbind(t, l.Addr().String(), "smsc", "secret")
+	defer c.Close()
+	p, err := c.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := pdu.NewBindReceiverResp()
+	resp.Header().Seq = p.Header().Seq
+	if err = c.Write(resp); err != nil {
+		t.Fatal(err)
+	}
+	if s := <-status; s.Status() != Connected {
+		t.Fatalf("unexpected status: want Connected, have %s (%v)",
+			s.Status(), s.Error())
+	}
+	go func() {
+		p, err := c.Read()
+		if err != nil || p.Header().ID != pdu.UnbindID {
+			return
+		}
+		c.Write(pdu.NewUnbindRespSeq(p.Header().Seq))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = r.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fd, err := net.Dial("tcp", l.Addr().String()); err == nil {
+		fd.Close()
+		t.Fatal("listener not closed")
+	}
+}
+
+func TestOutbindReceiverHandshakeTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &OutbindReceiver{
+		OutbindTimeout: 50 * time.Millisecond,
+		Listener:       l,
+		Receiver:       Receiver{TLS: &tls.Config{}},
+	}
+	defer r.Close()
+	status := r.Bind()
+	// Connect without starting the TLS handshake.
+	fd, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	select {
+	case s := <-status:
+		if s.Status() != ConnectionFailed {
+			t.Fatalf("unexpected status: %s (%v)", s.Status(), s.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TLS handshake did not time out")
+	}
+}
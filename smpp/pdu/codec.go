@@ -10,12 +10,18 @@ import (
 	"io"
 	"sync/atomic"
 
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
 )
 
 var nextSeq uint32
 
+// NextSeq returns a new sequence number, as assigned to PDUs when they
+// are created, e.g. for sending a PDU again as a new request.
+func NextSeq() uint32 {
+	return atomic.AddUint32(&nextSeq, 1)
+}
+
 // codec is the base type of all PDUs.
 // It implements the PDU interface and provides a generic encoder.
 type codec struct {
@@ -34,7 +40,7 @@ func (pdu *codec) init() {
 	pdu.f = make(pdufield.Map)
 	pdu.t = make(pdutlv.Map)
 	if pdu.h.Seq == 0 { // If Seq not set
-		pdu.h.Seq = atomic.AddUint32(&nextSeq, 1)
+		pdu.h.Seq = NextSeq()
 	}
 }
 
@@ -139,19 +145,27 @@ func Decode(r io.Reader) (Body, error) {
 	}
 	switch hdr.ID {
 	case AlertNotificationID:
-		// TODO(fiorix): Implement AlertNotification.
+		return decodeFields(newAlertNotification(hdr), b)
 	case BindReceiverID, BindTransceiverID, BindTransmitterID:
 		return decodeFields(newBind(hdr), b)
 	case BindReceiverRespID, BindTransceiverRespID, BindTransmitterRespID:
 		return decodeFields(newBindResp(hdr), b)
+	case BroadcastSMID:
+		return decodeFields(newBroadcastSM(hdr), b)
+	case BroadcastSMRespID:
+		return decodeFields(newBroadcastSMResp(hdr), b)
+	case CancelBroadcastSMID:
+		return decodeFields(newCancelBroadcastSM(hdr), b)
+	case CancelBroadcastSMRespID:
+		return decodeFields(newCancelBroadcastSMResp(hdr), b)
 	case CancelSMID:
-		// TODO(fiorix): Implement CancelSM.
+		return decodeFields(newCancelSM(hdr), b)
 	case CancelSMRespID:
-		// TODO(fiorix): Implement CancelSMResp.
+		return decodeFields(newCancelSMResp(hdr), b)
 	case DataSMID:
-		// TODO(fiorix): Implement DataSM.
+		return decodeFields(newDataSM(hdr), b)
 	case DataSMRespID:
-		// TODO(fiorix): Implement DataSMResp.
+		return decodeFields(newDataSMResp(hdr), b)
 	case DeliverSMID:
 		return decodeFields(newDeliverSM(hdr), b)
 	case DeliverSMRespID:
@@ -163,15 +177,19 @@ func Decode(r io.Reader) (Body, error) {
 	case GenericNACKID:
 		return decodeFields(newGenericNACK(hdr), b)
 	case OutbindID:
-		// TODO(fiorix): Implement Outbind.
+		return decodeFields(newOutbind(hdr), b)
+	case QueryBroadcastSMID:
+		return decodeFields(newQueryBroadcastSM(hdr), b)
+	case QueryBroadcastSMRespID:
+		return decodeFields(newQueryBroadcastSMResp(hdr), b)
 	case QuerySMID:
 		return decodeFields(newQuerySM(hdr), b)
 	case QuerySMRespID:
 		return decodeFields(newQuerySMResp(hdr), b)
 	case ReplaceSMID:
-		// TODO(fiorix): Implement ReplaceSM.
+		return decodeFields(newReplaceSM(hdr), b)
 	case ReplaceSMRespID:
-		// TODO(fiorix): Implement ReplaceSMResp.
+		return decodeFields(newReplaceSMResp(hdr), b)
 	case SubmitMultiID:
 		return decodeFields(newSubmitMulti(hdr), b)
 	case SubmitMultiRespID:
@@ -184,8 +202,6 @@ func Decode(r io.Reader) (Body, error) {
 		return decodeFields(newUnbind(hdr), b)
 	case UnbindRespID:
 		return decodeFields(newUnbindResp(hdr), b)
-	default:
-		return nil, fmt.Errorf("unknown PDU type: %#x", hdr.ID)
 	}
-	return nil, fmt.Errorf("PDU not implemented: %#x", hdr.ID)
+	return nil, fmt.Errorf("unknown PDU type: %#x", hdr.ID)
 }
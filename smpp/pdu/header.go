@@ -46,6 +46,13 @@ var idString = map[ID]string{
 	AlertNotificationID:   "AlertNotification",
 	DataSMID:              "DataSM",
 	DataSMRespID:          "DataSMResp",
+
+	BroadcastSMID:           "BroadcastSM",
+	BroadcastSMRespID:       "BroadcastSMResp",
+	QueryBroadcastSMID:      "QueryBroadcastSM",
+	QueryBroadcastSMRespID:  "QueryBroadcastSMResp",
+	CancelBroadcastSMID:     "CancelBroadcastSM",
+	CancelBroadcastSMRespID: "CancelBroadcastSMResp",
 }
 
 // String returns the PDU type as a string.
@@ -111,6 +118,33 @@ func (h *Header) SerializeTo(w io.Writer) error {
 	return err
 }
 
+// Command statuses used by the client and server.
+const (
+	StatusOK        Status = 0x00000000 // ESME_ROK
+	StatusInvCmdLen Status = 0x00000002 // ESME_RINVCMDLEN
+	StatusInvCmdID  Status = 0x00000003 // ESME_RINVCMDID
+	StatusInvBndSts Status = 0x00000004 // ESME_RINVBNDSTS
+	StatusAlyBnd    Status = 0x00000005 // ESME_RALYBND
+	StatusSysErr    Status = 0x00000008 // ESME_RSYSERR
+	StatusBindFail  Status = 0x0000000d // ESME_RBINDFAIL
+	StatusInvPaswd  Status = 0x0000000e // ESME_RINVPASWD
+	StatusInvSysID  Status = 0x0000000f // ESME_RINVSYSID
+	StatusMsgQFull  Status = 0x00000014 // ESME_RMSGQFUL
+	StatusThrottled Status = 0x00000058 // ESME_RTHROTTLED
+	StatusRxTAppn   Status = 0x00000064 // ESME_RX_T_APPN
+)
+
+// Temporary reports whether s is a transient failure, after which the
+// same request may succeed: system error, message queue full,
+// throttling and temporary application errors.
+func (s Status) Temporary() bool {
+	switch s {
+	case StatusSysErr, StatusMsgQFull, StatusThrottled, StatusRxTAppn:
+		return true
+	}
+	return false
+}
+
 // Error implements the Error interface.
 func (s Status) Error() string {
 	m, ok := esmeStatus[s]
@@ -27,6 +27,8 @@ func New(n Name, data []byte) Body {
 		DestAddrTON,
 		ESMClass,
 		ErrorCode,
+		EsmeAddrNPI,
+		EsmeAddrTON,
 		InterfaceVersion,
 		MessageState,
 		NumberDests,
@@ -48,6 +50,7 @@ func New(n Name, data []byte) Body {
 		AddressRange,
 		DestinationAddr,
 		DestinationList,
+		EsmeAddr,
 		FinalDate,
 		MessageID,
 		Password,
@@ -68,20 +71,9 @@ func New(n Name, data []byte) Body {
 		}
 		return &SM{Data: data}
 	case GSMUserData:
-		udhData := []UDH{}
-		if data != nil && len(data) > 2 {
-			for i := 0; i < len(data); {
-				udh := UDH{}
-				udh.IEI = Fixed{Data: data[i]}
-				udh.IELength = Fixed{Data: data[i+1]}
-				udh.IEData = Variable{}
-				l := int(data[i+1])
-				for j := 2; j < l+2; j++ {
-					udh.IEData.Data = append(udh.IEData.Data, data[i+j])
-				}
-				udhData = append(udhData, udh)
-				i += l + 3 // Ignore one byte after IEData (which is 0x00)
-			}
+		udhData, _ := ParseIEs(data)
+		if udhData == nil {
+			udhData = []UDH{}
 		}
 		return &UDHList{Data: udhData}
 	default:
@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
 )
 
 // List is a list of PDU fields.
@@ -16,8 +18,8 @@ type List []Name
 // Decode decodes binary data in the given buffer to build a Map.
 //
 // If the ShortMessage field is present, and DataCoding as well,
-// we attempt to decode text automatically. See pdutext package
-// for more information.
+// we attempt to decode text automatically, and set the codec of the
+// text in SM.Text. See pdutext package for more information.
 func (l List) Decode(r *bytes.Buffer) (Map, error) {
 	var (
 		unsuccessCount, numDest, udhLength, smLength int
@@ -32,6 +34,7 @@ loop:
 			AddressRange,
 			DestinationAddr,
 			ErrorCode,
+			EsmeAddr,
 			FinalDate,
 			MessageID,
 			MessageState,
@@ -57,6 +60,8 @@ loop:
 			DestAddrNPI,
 			DestAddrTON,
 			ESMClass,
+			EsmeAddrNPI,
+			EsmeAddrTON,
 			InterfaceVersion,
 			NumberDests,
 			NoUnsuccess,
@@ -231,8 +236,30 @@ loop:
 				return nil, fmt.Errorf("short read for smlength: want %d, have %d",
 					smLength, r.Len())
 			}
-			f[ShortMessage] = &SM{Data: r.Next(smLength)}
+			sm := &SM{Data: r.Next(smLength)}
+			if dc, ok := f[DataCoding].(*Fixed); ok {
+				sm.Text = newCodec(pdutext.DataCoding(dc.Data), udhiFlag && udhLength == 0, sm.Data)
+			}
+			f[ShortMessage] = sm
 		}
 	}
 	return f, nil
 }
+
+// newCodec returns the codec of the text of short_message data with the
+// given data_coding, skipping the user data header if data has one.
+// GSM 7-bit text uses the national language shift tables set in the
+// header, if any.
+func newCodec(dc pdutext.DataCoding, udhi bool, data []byte) pdutext.Codec {
+	var udh []byte
+	if udhi {
+		if h, text, err := ParseUDH(data); err == nil {
+			udh, data = h.Bytes(), text
+		}
+	}
+	c := pdutext.NewCodec(dc, data)
+	if _, ok := c.(pdutext.GSM7); ok && len(udh) > 0 {
+		return pdutext.NewGSM7National(udh, data)
+	}
+	return c
+}
@@ -73,6 +73,43 @@ func TestListDecoder_SM(t *testing.T) {
 	}
 }
 
+func TestListDecoder_SMText(t *testing.T) {
+	l := List{ESMClass, DataCoding, SMLength, ShortMessage}
+	test := []struct {
+		esm  byte
+		dc   byte
+		data []byte
+		want string
+	}{
+		{0x00, 0x00, []byte{0x00, 'h', 'i'}, "@hi"},
+		{0x00, 0x03, []byte("ol\xe1"), "olá"},
+		{0x00, 0x08, []byte{0x00, 'o', 0x00, 'l', 0x00, 0xe1}, "olá"},
+		{0x00, 0x18, []byte{0x04, 0x3f}, "п"},
+		{0x00, 0xf0, []byte{0x00, 'h', 'i'}, "@hi"},
+		{0x00, 0xf4, []byte{0xff}, "\xff"},
+		{0x00, 0x09, []byte{0xff}, "\xff"},
+		{0x40, 0x08, []byte{0x05, 0x00, 0x03, 0x01, 0x02, 0x01, 0x00, 'h', 0x00, 'i'}, "hi"},
+		{0x40, 0x00, []byte{0x03, 0x25, 0x01, 0x01, 0x07}, "ı"},
+	}
+	for _, tc := range test {
+		b := bytes.NewBuffer(append([]byte{tc.esm, tc.dc, byte(len(tc.data))}, tc.data...))
+		m, err := l.Decode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, ok := m[ShortMessage].(*SM)
+		if !ok {
+			t.Fatalf("field is not type SM: %#v", m[ShortMessage])
+		}
+		if v.Text == nil {
+			t.Fatalf("missing text codec for data_coding %#x", tc.dc)
+		}
+		if have := string(v.Text.Decode()); have != tc.want {
+			t.Fatalf("unexpected text for data_coding %#x: want %q, have %q", tc.dc, tc.want, have)
+		}
+	}
+}
+
 func TestListDecoder_DestinationList(t *testing.T) {
 	l := List{NumberDests, DestinationList}
 	want := []byte{0x02, 0x01, 0x01, 0x01, '1', '2', '3', 0x00, 0x01, 0x01, 0x01, '5', '6', '7', 0x00}
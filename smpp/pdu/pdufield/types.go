@@ -8,6 +8,8 @@ import (
 	"encoding/binary"
 	"io"
 	"strconv"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
 )
 
 // Name is the name of a PDU field.
@@ -24,6 +26,9 @@ const (
 	DestinationAddr      Name = "destination_addr"
 	DestinationList      Name = "dest_addresses"
 	ESMClass             Name = "esm_class"
+	EsmeAddr             Name = "esme_addr"
+	EsmeAddrNPI          Name = "esme_addr_npi"
+	EsmeAddrTON          Name = "esme_addr_ton"
 	ErrorCode            Name = "error_code"
 	FinalDate            Name = "final_date"
 	InterfaceVersion     Name = "interface_version"
@@ -123,6 +128,10 @@ func (v *Variable) SerializeTo(w io.Writer) error {
 // SM is a PDU field used for Short Messages.
 type SM struct {
 	Data []byte
+
+	// Text is the codec of the text in Data, without the user data
+	// header, as set by List.Decode from the data_coding field.
+	Text pdutext.Codec
 }
 
 // Len implements the Data interface.
@@ -335,7 +344,7 @@ type UDH struct {
 
 // Len implements the Data interface.
 func (udh *UDH) Len() int {
-	return udh.IEI.Len() + udh.IELength.Len() + udh.IEData.Len()
+	return udh.IEI.Len() + udh.IELength.Len() + len(udh.IEData.Data)
 }
 
 // Raw implements the Data interface.
@@ -353,7 +362,7 @@ func (udh *UDH) Bytes() []byte {
 	var ret []byte
 	ret = append(ret, udh.IEI.Bytes()...)
 	ret = append(ret, udh.IELength.Bytes()...)
-	ret = append(ret, udh.IEData.Bytes()...)
+	ret = append(ret, udh.IEData.Data...) // octet string, not null terminated
 	return ret
 }
 
@@ -0,0 +1,204 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdufield
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+)
+
+// Information element identifiers of the user data header, from
+// 3GPP TS 23.040 section 9.2.3.24.
+const (
+	IEIConcat8      = 0x00 // Concatenated short messages, 8-bit reference
+	IEISpecialSMS   = 0x01 // Special SMS message indication
+	IEIPort8        = 0x04 // Application port addressing, 8-bit ports
+	IEIPort16       = 0x05 // Application port addressing, 16-bit ports
+	IEIConcat16     = 0x08 // Concatenated short messages, 16-bit reference
+	IEISingleShift  = pdutext.IEISingleShift
+	IEILockingShift = pdutext.IEILockingShift
+)
+
+// Message types of the special SMS message indication.
+const (
+	VoicemailWaiting = 0x00
+	FaxWaiting       = 0x01
+	EmailWaiting     = 0x02
+	OtherWaiting     = 0x03
+)
+
+// Ports of WAP Push, the connectionless WSP port of the handset and
+// the WAP Push originator.
+const (
+	WAPPushPort       = 2948
+	WAPPushOriginPort = 9200
+)
+
+// NewIE returns the information element iei with the given data.
+func NewIE(iei uint8, data []byte) UDH {
+	return UDH{
+		IEI:      Fixed{Data: iei},
+		IELength: Fixed{Data: uint8(len(data))},
+		IEData:   Variable{Data: data},
+	}
+}
+
+// NewConcat8IE returns the concatenation information element of part
+// seq of total parts of the long message with 8-bit reference ref.
+func NewConcat8IE(ref, total, seq uint8) UDH {
+	return NewIE(IEIConcat8, []byte{ref, total, seq})
+}
+
+// NewConcat16IE returns the concatenation information element of part
+// seq of total parts of the long message with 16-bit reference ref.
+func NewConcat16IE(ref uint16, total, seq uint8) UDH {
+	return NewIE(IEIConcat16, []byte{uint8(ref >> 8), uint8(ref), total, seq})
+}
+
+// NewPort8IE returns the application port addressing information
+// element with 8-bit destination and source ports.
+func NewPort8IE(dst, src uint8) UDH {
+	return NewIE(IEIPort8, []byte{dst, src})
+}
+
+// NewPort16IE returns the application port addressing information
+// element with 16-bit destination and source ports.
+func NewPort16IE(dst, src uint16) UDH {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint16(b, dst)
+	binary.BigEndian.PutUint16(b[2:], src)
+	return NewIE(IEIPort16, b)
+}
+
+// NewWAPPushIE returns the application port addressing information
+// element of WAP Push messages.
+func NewWAPPushIE() UDH {
+	return NewPort16IE(WAPPushPort, WAPPushOriginPort)
+}
+
+// NewSpecialSMSIE returns the special SMS message indication of count
+// messages waiting of the given type, e.g. VoicemailWaiting. If store
+// is true the handset stores the message, otherwise it may discard it
+// after updating the indication. A count of 0 clears the indication.
+func NewSpecialSMSIE(typ, count uint8, store bool) UDH {
+	typ &= 0x7f
+	if store {
+		typ |= 0x80
+	}
+	return NewIE(IEISpecialSMS, []byte{typ, count})
+}
+
+// NewSingleShiftIE returns the national language single shift
+// information element of language l.
+func NewSingleShiftIE(l encoding.Language) UDH {
+	return NewIE(IEISingleShift, []byte{uint8(l)})
+}
+
+// NewLockingShiftIE returns the national language locking shift
+// information element of language l.
+func NewLockingShiftIE(l encoding.Language) UDH {
+	return NewIE(IEILockingShift, []byte{uint8(l)})
+}
+
+// NewUDHList returns the user data header with the given information
+// elements.
+func NewUDHList(ies ...UDH) *UDHList {
+	return &UDHList{Data: ies}
+}
+
+// ParseIEs parses the information elements of a user data header,
+// without the header length.
+func ParseIEs(b []byte) ([]UDH, error) {
+	var ies []UDH
+	for i := 0; i < len(b); {
+		if i+2 > len(b) {
+			return nil, fmt.Errorf("short read for information element at %d", i)
+		}
+		l := int(b[i+1])
+		if i+2+l > len(b) {
+			return nil, fmt.Errorf("short read for information element %#x: want %d, have %d",
+				b[i], l, len(b)-i-2)
+		}
+		ies = append(ies, NewIE(b[i], b[i+2:i+2+l]))
+		i += 2 + l
+	}
+	return ies, nil
+}
+
+// ParseUDH parses the user data header at the start of user data, as
+// in the short_message of PDUs with the UDHI flag of esm_class set, and
+// returns it along with the rest of the user data.
+func ParseUDH(ud []byte) (*UDHList, []byte, error) {
+	if len(ud) == 0 {
+		return nil, nil, fmt.Errorf("missing user data header")
+	}
+	l := int(ud[0]) + 1
+	if l > len(ud) {
+		return nil, nil, fmt.Errorf("short read for user data header: want %d, have %d",
+			l-1, len(ud)-1)
+	}
+	ies, err := ParseIEs(ud[1:l])
+	if err != nil {
+		return nil, nil, err
+	}
+	return &UDHList{Data: ies}, ud[l:], nil
+}
+
+// Find returns the first information element iei, or nil.
+func (udhl *UDHList) Find(iei uint8) *UDH {
+	for i := range udhl.Data {
+		if udhl.Data[i].IEI.Data == iei {
+			return &udhl.Data[i]
+		}
+	}
+	return nil
+}
+
+// Concat returns the reference number, total number of parts and
+// sequence number of the concatenation information element, with 8
+// or 16-bit reference, if any.
+func (udhl *UDHList) Concat() (ref, total, seq int, ok bool) {
+	for _, ie := range udhl.Data {
+		d := ie.IEData.Data
+		switch {
+		case ie.IEI.Data == IEIConcat8 && len(d) == 3:
+			return int(d[0]), int(d[1]), int(d[2]), true
+		case ie.IEI.Data == IEIConcat16 && len(d) == 4:
+			return int(binary.BigEndian.Uint16(d)), int(d[2]), int(d[3]), true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// Header returns the user data header, prefixed with its length, or
+// nil if there are no information elements.
+func (udhl *UDHList) Header() []byte {
+	if len(udhl.Data) == 0 {
+		return nil
+	}
+	b := udhl.Bytes()
+	return append([]byte{uint8(len(b))}, b...)
+}
+
+// UserData returns the user data header followed by the text encoded
+// with c, for the short_message of PDUs with the UDHI flag of
+// esm_class set. GSM 7-bit packed text starts at the septet boundary
+// that follows the header, with fill bits in between.
+func (udhl *UDHList) UserData(c pdutext.Codec) []byte {
+	h := udhl.Header()
+	s, ok := c.(pdutext.GSM7Packed)
+	if !ok || len(h) == 0 {
+		return append(h, c.Encode()...)
+	}
+	// Pack the text after as many '@' (septet 0x00) as septets the
+	// header takes, and replace their octets with the header.
+	n := (len(h)*8 + 6) / 7
+	b := pdutext.GSM7Packed(append(bytes.Repeat([]byte("@"), n), s...)).Encode()
+	return append(h, b[len(h):]...)
+}
@@ -0,0 +1,93 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdufield
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+)
+
+func TestUDHListHeader(t *testing.T) {
+	test := []struct {
+		udh  *UDHList
+		want []byte
+	}{
+		{NewUDHList(), nil},
+		{NewUDHList(NewConcat8IE(0x12, 3, 1)), []byte{0x05, 0x00, 0x03, 0x12, 0x03, 0x01}},
+		{NewUDHList(NewConcat16IE(0x1234, 3, 2)), []byte{0x06, 0x08, 0x04, 0x12, 0x34, 0x03, 0x02}},
+		{NewUDHList(NewPort8IE(0xf5, 0x00)), []byte{0x04, 0x04, 0x02, 0xf5, 0x00}},
+		{NewUDHList(NewWAPPushIE()), []byte{0x06, 0x05, 0x04, 0x0b, 0x84, 0x23, 0xf0}},
+		{NewUDHList(NewSpecialSMSIE(VoicemailWaiting, 2, true)), []byte{0x04, 0x01, 0x02, 0x80, 0x02}},
+		{
+			NewUDHList(NewSingleShiftIE(encoding.Turkish), NewLockingShiftIE(encoding.Turkish)),
+			[]byte{0x06, 0x24, 0x01, 0x01, 0x25, 0x01, 0x01},
+		},
+	}
+	for _, tc := range test {
+		if have := tc.udh.Header(); !bytes.Equal(have, tc.want) {
+			t.Fatalf("unexpected header: want %x, have %x", tc.want, have)
+		}
+	}
+}
+
+func TestParseUDH(t *testing.T) {
+	ud := []byte{0x0b, 0x05, 0x04, 0x0b, 0x84, 0x23, 0xf0, 0x00, 0x03, 0x7f, 0x02, 0x01, 'h', 'i'}
+	udh, data, err := ParseUDH(ud)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hi" {
+		t.Fatalf("unexpected data: %q", data)
+	}
+	if len(udh.Data) != 2 {
+		t.Fatalf("unexpected information elements: %v", udh)
+	}
+	if ie := udh.Find(IEIPort16); ie == nil || !bytes.Equal(ie.IEData.Data, []byte{0x0b, 0x84, 0x23, 0xf0}) {
+		t.Fatalf("unexpected port information element: %v", ie)
+	}
+	if ie := udh.Find(IEIConcat16); ie != nil {
+		t.Fatalf("unexpected information element: %v", ie)
+	}
+	if ref, total, seq, ok := udh.Concat(); !ok || ref != 0x7f || total != 2 || seq != 1 {
+		t.Fatalf("unexpected concatenation: %d %d %d %t", ref, total, seq, ok)
+	}
+	if have := udh.Header(); !bytes.Equal(have, ud[:12]) {
+		t.Fatalf("unexpected header: want %x, have %x", ud[:12], have)
+	}
+	for _, ud := range [][]byte{
+		{},
+		{0x05, 0x00, 0x03, 0x01},
+		{0x03, 0x00, 0x03, 0x01},
+		{0x01, 0x00},
+	} {
+		if _, _, err := ParseUDH(ud); err == nil {
+			t.Fatalf("unexpected user data header in %x", ud)
+		}
+	}
+}
+
+func TestUDHListUserData(t *testing.T) {
+	udh := NewUDHList(NewConcat8IE(0x01, 2, 1))
+	test := []struct {
+		text pdutext.Codec
+		want []byte
+	}{
+		{pdutext.Raw("hi"), []byte{0x05, 0x00, 0x03, 0x01, 0x02, 0x01, 'h', 'i'}},
+		{pdutext.UCS2("hi"), []byte{0x05, 0x00, 0x03, 0x01, 0x02, 0x01, 0x00, 'h', 0x00, 'i'}},
+		// One fill bit between the header and the first septet.
+		{pdutext.GSM7Packed("hi"), []byte{0x05, 0x00, 0x03, 0x01, 0x02, 0x01, 0xd0, 0x69}},
+	}
+	for _, tc := range test {
+		if have := udh.UserData(tc.text); !bytes.Equal(have, tc.want) {
+			t.Fatalf("unexpected user data for %T: want %x, have %x", tc.text, tc.want, have)
+		}
+	}
+	if have := NewUDHList().UserData(pdutext.GSM7Packed("hi")); !bytes.Equal(have, pdutext.GSM7Packed("hi").Encode()) {
+		t.Fatalf("unexpected user data without header: %x", have)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+// Binary codec, 8-bit data that is sent and received as is.
+type Binary []byte
+
+// Type implements the Codec interface.
+func (s Binary) Type() DataCoding {
+	return BinaryType
+}
+
+// Encode binary data.
+func (s Binary) Encode() []byte {
+	return s
+}
+
+// Decode binary data.
+func (s Binary) Decode() []byte {
+	return s
+}
+
+// Binary2 codec, like Binary with data_coding 0x04, which is also the
+// 8-bit data coding of the GSM data coding scheme.
+type Binary2 []byte
+
+// Type implements the Codec interface.
+func (s Binary2) Type() DataCoding {
+	return Binary2Type
+}
+
+// Encode binary data.
+func (s Binary2) Encode() []byte {
+	return s
+}
+
+// Decode binary data.
+func (s Binary2) Decode() []byte {
+	return s
+}
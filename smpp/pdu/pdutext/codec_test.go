@@ -18,6 +18,14 @@ func TestEncode(t *testing.T) {
 		{Latin1([]byte("áéíóú moço")), []byte("\xe1\xe9\xed\xf3\xfa mo\xe7o")},
 		{UCS2([]byte("áéíóú moço")), []byte("\x00\xe1\x00\xe9\x00\xed\x00\xf3\x00\xfa\x00 \x00m\x00o\x00\xe7\x00o")},
 		{ISO88595([]byte(iso88595UTF8Bytes)), []byte(iso88595Bytes)},
+		{IA5([]byte("hello é")), []byte("hello ?")},
+		{Binary([]byte("\x00\xff")), []byte("\x00\xff")},
+		{Binary2([]byte("\x00\xff")), []byte("\x00\xff")},
+		{ISO88598([]byte("שלום")), []byte("\xf9\xec\xe5\xed")},
+		{JIS([]byte("日本")), []byte("\x93\xfa\x96\x7b")},
+		{ISO2022JP([]byte("日本")), []byte("\x1b$BF|K\\\x1b(B")},
+		{EXTJIS([]byte("日本")), []byte("\xc6\xfc\xcb\xdc")},
+		{KSC5601([]byte("한국")), []byte("\xc7\xd1\xb1\xb9")},
 	}
 	for _, tc := range test {
 		have := tc.codec.Encode()
@@ -36,6 +44,14 @@ func TestDecode(t *testing.T) {
 		{[]byte("áéíóú moço"), Latin1([]byte("\xe1\xe9\xed\xf3\xfa mo\xe7o"))},
 		{[]byte("áéíóú moço"), UCS2([]byte("\x00\xe1\x00\xe9\x00\xed\x00\xf3\x00\xfa\x00 \x00m\x00o\x00\xe7\x00o"))},
 		{[]byte(iso88595UTF8Bytes), ISO88595([]byte(iso88595Bytes))},
+		{[]byte("hi\ufffd"), IA5([]byte("hi\xff"))},
+		{[]byte("\x00\xff"), Binary([]byte("\x00\xff"))},
+		{[]byte("\x00\xff"), Binary2([]byte("\x00\xff"))},
+		{[]byte("שלום"), ISO88598([]byte("\xf9\xec\xe5\xed"))},
+		{[]byte("日本"), JIS([]byte("\x93\xfa\x96\x7b"))},
+		{[]byte("日本"), ISO2022JP([]byte("\x1b$BF|K\\\x1b(B"))},
+		{[]byte("日本"), EXTJIS([]byte("\xc6\xfc\xcb\xdc"))},
+		{[]byte("한국"), KSC5601([]byte("\xc7\xd1\xb1\xb9"))},
 	}
 	for _, tc := range test {
 		have := tc.codec.Decode()
@@ -7,7 +7,11 @@
 // See 2.2.2 from http://opensmpp.org/specs/smppv34_gsmumts_ig_v10.pdf
 // for details.
 //
-// pdutext supports Latin1 (0x03) and UCS2 (0x08).
+// pdutext supports GSM 7-bit (0x00), IA5 (0x01), binary (0x02 and 0x04),
+// Latin1 (0x03), JIS (0x05), ISO-8859-5 (0x06), ISO-8859-8 (0x07),
+// UCS2 (0x08), ISO-2022-JP (0x0A), extended Kanji JIS (0x0D) and
+// KS C 5601 (0x0E). NewCodec returns the codec of received text for any
+// data_coding, including the GSM data coding scheme groups.
 //
 // Latin1 encoding is Windows-1252 (CP1252) for now, not ISO-8859-1.
 // http://www.i18nqa.com/debug/table-iso8859-1-vs-windows-1252.html
@@ -0,0 +1,38 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// EXTJIS text codec, extended Kanji JIS X 0212 in its EUC-JP encoding.
+type EXTJIS []byte
+
+// Type implements the Codec interface.
+func (s EXTJIS) Type() DataCoding {
+	return EXTJISType
+}
+
+// Encode to EUC-JP.
+func (s EXTJIS) Encode() []byte {
+	e := japanese.EUCJP.NewEncoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
+
+// Decode from EUC-JP.
+func (s EXTJIS) Decode() []byte {
+	e := japanese.EUCJP.NewDecoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"golang.org/x/text/transform"
+
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+)
+
+// Information element identifiers of the national language shift
+// tables in the user data header.
+const (
+	IEISingleShift  = 0x24
+	IEILockingShift = 0x25
+)
+
+// HeaderCodec is a Codec whose parameters must be signalled in the
+// user data header of every short message, along with the UDHI flag
+// of esm_class.
+type HeaderCodec interface {
+	Codec
+
+	// Header returns the information elements for the user data
+	// header, without the header length.
+	Header() []byte
+}
+
+// GSM7National is GSM 7-bit (unpacked) text using the locking and
+// single shift tables of national languages.
+type GSM7National struct {
+	Text    []byte
+	Locking encoding.Language
+	Single  encoding.Language
+}
+
+// NewGSM7National returns the GSM7National text of the given user data
+// header and text, using the shift tables set in the header, if any.
+func NewGSM7National(udh, text []byte) GSM7National {
+	s := GSM7National{Text: text}
+	for i := 0; i+2 < len(udh); i += int(udh[i+1]) + 2 {
+		if udh[i+1] != 1 {
+			continue
+		}
+		switch udh[i] {
+		case IEISingleShift:
+			s.Single = encoding.Language(udh[i+2])
+		case IEILockingShift:
+			s.Locking = encoding.Language(udh[i+2])
+		}
+	}
+	return s
+}
+
+// Type implements the Codec interface.
+func (s GSM7National) Type() DataCoding {
+	return DefaultType
+}
+
+// Encode to GSM 7-bit (unpacked)
+func (s GSM7National) Encode() []byte {
+	e := encoding.GSM7National(false, s.Locking, s.Single).NewEncoder()
+	es, _, err := transform.Bytes(e, s.Text)
+	if err != nil {
+		return s.Text
+	}
+	return es
+}
+
+// Decode from GSM 7-bit (unpacked)
+func (s GSM7National) Decode() []byte {
+	e := encoding.GSM7National(false, s.Locking, s.Single).NewDecoder()
+	es, _, err := transform.Bytes(e, s.Text)
+	if err != nil {
+		return s.Text
+	}
+	return es
+}
+
+// Header implements the HeaderCodec interface.
+func (s GSM7National) Header() []byte {
+	var h []byte
+	if s.Single != encoding.DefaultLanguage {
+		h = append(h, IEISingleShift, 1, byte(s.Single))
+	}
+	if s.Locking != encoding.DefaultLanguage {
+		h = append(h, IEILockingShift, 1, byte(s.Locking))
+	}
+	return h
+}
@@ -0,0 +1,42 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+)
+
+func TestGSM7NationalEncoder(t *testing.T) {
+	want := []byte("\x49\x1d\x07\x6b")
+	s := GSM7National{Text: []byte("Işık"), Locking: encoding.Turkish, Single: encoding.Turkish}
+	if s.Type() != 0x00 {
+		t.Fatalf("Unexpected data type; want 0x00, have %d", s.Type())
+	}
+	have := s.Encode()
+	if !bytes.Equal(want, have) {
+		t.Fatalf("Unexpected text; want %q, have %q", want, have)
+	}
+	wantUDH := []byte{IEISingleShift, 1, 1, IEILockingShift, 1, 1}
+	if udh := s.Header(); !bytes.Equal(wantUDH, udh) {
+		t.Fatalf("Unexpected header; want %x, have %x", wantUDH, udh)
+	}
+}
+
+func TestGSM7NationalDecoder(t *testing.T) {
+	want := []byte("Işık")
+	// concatenation information element followed by the shift tables.
+	udh := []byte{0x00, 0x03, 0x01, 0x02, 0x01, IEILockingShift, 1, 1, IEISingleShift, 1, 2}
+	s := NewGSM7National(udh, []byte("\x49\x1d\x07\x6b"))
+	if s.Locking != encoding.Turkish || s.Single != encoding.Spanish {
+		t.Fatalf("Unexpected languages: %s, %s", s.Locking, s.Single)
+	}
+	have := s.Decode()
+	if !bytes.Equal(want, have) {
+		t.Fatalf("Unexpected text; want %q, have %q", want, have)
+	}
+}
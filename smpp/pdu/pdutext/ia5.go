@@ -0,0 +1,43 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"unicode/utf8"
+)
+
+// IA5 text codec, the international version of ASCII.
+type IA5 []byte
+
+// Type implements the Codec interface.
+func (s IA5) Type() DataCoding {
+	return IA5Type
+}
+
+// Encode to IA5. Characters outside of ASCII are replaced by '?'.
+func (s IA5) Encode() []byte {
+	es := make([]byte, 0, len(s))
+	for _, r := range string(s) {
+		if r >= utf8.RuneSelf {
+			r = '?'
+		}
+		es = append(es, byte(r))
+	}
+	return es
+}
+
+// Decode from IA5. Octets outside of ASCII are replaced by the Unicode
+// replacement character.
+func (s IA5) Decode() []byte {
+	es := make([]byte, 0, len(s))
+	for _, c := range s {
+		if c >= utf8.RuneSelf {
+			es = append(es, string(utf8.RuneError)...)
+			continue
+		}
+		es = append(es, c)
+	}
+	return es
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// ISO88598 text codec.
+type ISO88598 []byte
+
+// Type implements the Codec interface.
+func (s ISO88598) Type() DataCoding {
+	return ISO88598Type
+}
+
+// Encode to ISO88598.
+func (s ISO88598) Encode() []byte {
+	e := charmap.ISO8859_8.NewEncoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
+
+// Decode from ISO88598.
+func (s ISO88598) Decode() []byte {
+	e := charmap.ISO8859_8.NewDecoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
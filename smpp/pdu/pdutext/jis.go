@@ -0,0 +1,38 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// JIS text codec, JIS X 0208 in its Shift_JIS encoding.
+type JIS []byte
+
+// Type implements the Codec interface.
+func (s JIS) Type() DataCoding {
+	return JISType
+}
+
+// Encode to Shift_JIS.
+func (s JIS) Encode() []byte {
+	e := japanese.ShiftJIS.NewEncoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
+
+// Decode from Shift_JIS.
+func (s JIS) Decode() []byte {
+	e := japanese.ShiftJIS.NewDecoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/transform"
+)
+
+// KSC5601 text codec, KS C 5601 in its EUC-KR encoding.
+type KSC5601 []byte
+
+// Type implements the Codec interface.
+func (s KSC5601) Type() DataCoding {
+	return KSC5601Type
+}
+
+// Encode to EUC-KR.
+func (s KSC5601) Encode() []byte {
+	e := korean.EUCKR.NewEncoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
+
+// Decode from EUC-KR.
+func (s KSC5601) Decode() []byte {
+	e := korean.EUCKR.NewDecoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
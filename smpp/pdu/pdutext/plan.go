@@ -0,0 +1,121 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+)
+
+// Segment limits, in characters of each codec: septets for GSM7 and
+// UTF-16 code units for UCS2. Segments of long messages leave room for
+// the 7 octets of a user data header with a 16-bit reference number.
+const (
+	MaxGSM7Len        = 160
+	MaxGSM7SegmentLen = 152
+	MaxUCS2Len        = 70
+	MaxUCS2SegmentLen = 66
+)
+
+// Plan is the result of planning the delivery of a text.
+type Plan struct {
+	Codec    Codec   // Codec of the whole text, GSM7, GSM7National or UCS2.
+	Len      int     // Length of the text in septets (GSM7) or code units (UCS2).
+	Segments []Codec // Text of each segment, a single one for short messages.
+}
+
+// NewPlan chooses the codec for text, GSM7 if all characters are in
+// the GSM 7-bit default alphabet or its extension table and UCS2
+// otherwise, and splits text in segments that fit in a short message.
+//
+// If national languages are given and text can not be represented in
+// the default alphabet, their shift tables are tried as well, and the
+// codec that takes the fewest segments is used. UCS2 is preferred when
+// it takes as many segments, since not all handsets support national
+// languages. GSM7National segments leave room for the shift table
+// information elements in the user data header.
+//
+// Characters from the extension table take two septets and characters
+// outside the Basic Multilingual Plane take two UTF-16 code units, and
+// neither are ever split between segments.
+func NewPlan(text string, langs ...encoding.Language) *Plan {
+	return NewPlanWithHeader(text, 0, langs...)
+}
+
+// NewPlanWithHeader is like NewPlan but leaves room for ieLen octets of
+// other information elements in the user data header of every short
+// message, e.g. application port addressing.
+func NewPlanWithHeader(text string, ieLen int, langs ...encoding.Language) *Plan {
+	gsm7Max, ucs2Max := MaxGSM7Len, MaxUCS2Len
+	if ieLen > 0 {
+		gsm7Max, ucs2Max = gsm7Len(1+ieLen), (140-1-ieLen)/2
+	}
+	if len(encoding.ValidateGSM7String(text)) == 0 {
+		return plan(text, GSM7(text), gsm7Max, gsm7Len(7+ieLen), encoding.GSM7RuneLen,
+			func(s string) Codec { return GSM7(s) })
+	}
+	best := plan(text, UCS2(text), ucs2Max, (140-7-ieLen)/2, ucs2Len,
+		func(s string) Codec { return UCS2(s) })
+	for _, l := range langs {
+		// Single shift alone takes less room in the header.
+		for _, locking := range []encoding.Language{encoding.DefaultLanguage, l} {
+			if p := planNational(text, ieLen, locking, l); p != nil && len(p.Segments) < len(best.Segments) {
+				best = p
+			}
+		}
+	}
+	return best
+}
+
+// planNational returns the plan for text with the given shift tables,
+// or nil if text can not be represented with them.
+func planNational(text string, ieLen int, locking, single encoding.Language) *Plan {
+	if !locking.HasLockingShift() || !single.HasSingleShift() ||
+		len(encoding.ValidateGSM7NationalString(text, locking, single)) > 0 {
+		return nil
+	}
+	c := GSM7National{Text: []byte(text), Locking: locking, Single: single}
+	ies := len(c.Header()) + ieLen
+	return plan(text, c, gsm7Len(1+ies), gsm7Len(7+ies),
+		func(r rune) int { return encoding.GSM7NationalRuneLen(r, locking, single) },
+		func(s string) Codec { return GSM7National{Text: []byte(s), Locking: locking, Single: single} })
+}
+
+// gsm7Len returns the number of septets that fit in a short message
+// with a user data header of the given length, in octets.
+func gsm7Len(udhLen int) int {
+	return (140 - udhLen) * 8 / 7
+}
+
+// plan splits text in segments of at most segLen characters, if it
+// does not fit in maxLen characters, as measured by runeLen.
+func plan(text string, c Codec, maxLen, segLen int, runeLen func(rune) int, codec func(string) Codec) *Plan {
+	p := &Plan{Codec: c}
+	for _, r := range text {
+		p.Len += runeLen(r)
+	}
+	if p.Len <= maxLen {
+		p.Segments = []Codec{c}
+		return p
+	}
+	start, n := 0, 0
+	for i, r := range text {
+		l := runeLen(r)
+		if n+l > segLen {
+			p.Segments = append(p.Segments, codec(text[start:i]))
+			start, n = i, 0
+		}
+		n += l
+	}
+	p.Segments = append(p.Segments, codec(text[start:]))
+	return p
+}
+
+// ucs2Len returns the number of UTF-16 code units of r.
+func ucs2Len(r rune) int {
+	if r > 0xFFFF {
+		return 2 // surrogate pair
+	}
+	return 1
+}
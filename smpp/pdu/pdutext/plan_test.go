@@ -0,0 +1,132 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+)
+
+func TestNewPlan(t *testing.T) {
+	test := []struct {
+		text string
+		typ  DataCoding
+		len  int
+		segs []int // length of each segment, in encoded bytes
+	}{
+		{text: "", typ: DefaultType, len: 0, segs: []int{0}},
+		{text: strings.Repeat("a", 160), typ: DefaultType, len: 160, segs: []int{160}},
+		{text: strings.Repeat("a", 161), typ: DefaultType, len: 161, segs: []int{152, 9}},
+		// escape characters take two septets and are never split.
+		{text: strings.Repeat("€", 80), typ: DefaultType, len: 160, segs: []int{160}},
+		{text: strings.Repeat("a", 151) + "€" + strings.Repeat("b", 10), typ: DefaultType, len: 163, segs: []int{151, 12}},
+		{text: strings.Repeat("ç", 70), typ: UCS2Type, len: 70, segs: []int{140}},
+		{text: strings.Repeat("ç", 71), typ: UCS2Type, len: 71, segs: []int{132, 10}},
+		// surrogate pairs take two code units and are never split.
+		{text: strings.Repeat("ç", 65) + "😀" + strings.Repeat("ç", 5), typ: UCS2Type, len: 72, segs: []int{130, 14}},
+	}
+	for _, tc := range test {
+		p := NewPlan(tc.text)
+		if p.Codec.Type() != tc.typ {
+			t.Fatalf("%q: unexpected data coding: want %#x, have %#x", tc.text, tc.typ, p.Codec.Type())
+		}
+		if p.Len != tc.len {
+			t.Fatalf("%q: unexpected length: want %d, have %d", tc.text, tc.len, p.Len)
+		}
+		if len(p.Segments) != len(tc.segs) {
+			t.Fatalf("%q: unexpected number of segments: want %d, have %d", tc.text, len(tc.segs), len(p.Segments))
+		}
+		var text string
+		for i, seg := range p.Segments {
+			if seg.Type() != tc.typ {
+				t.Fatalf("%q: unexpected data coding of segment %d: %#x", tc.text, i, seg.Type())
+			}
+			if l := len(seg.Encode()); l != tc.segs[i] {
+				t.Fatalf("%q: unexpected length of segment %d: want %d, have %d", tc.text, i, tc.segs[i], l)
+			}
+			text += string(seg.Encode())
+		}
+		if len(p.Segments) == 1 {
+			continue
+		}
+		if have := string(p.Codec.Encode()); have != text {
+			t.Fatalf("%q: segments do not add up to the text: %q", tc.text, text)
+		}
+	}
+}
+
+func TestNewPlanNational(t *testing.T) {
+	test := []struct {
+		text  string
+		langs []encoding.Language
+		codec Codec
+		segs  int
+	}{
+		// ties are sent as UCS2.
+		{"Işık", []encoding.Language{encoding.Turkish}, UCS2(""), 1},
+		// single shift, with 4 octets of header.
+		{strings.Repeat("ı", 77), []encoding.Language{encoding.Turkish},
+			GSM7National{Locking: encoding.DefaultLanguage, Single: encoding.Turkish}, 1},
+		{strings.Repeat("ı", 78), []encoding.Language{encoding.Turkish},
+			GSM7National{Locking: encoding.Turkish, Single: encoding.Turkish}, 1},
+		// locking shift, with 13 octets of header in long messages.
+		{strings.Repeat("ı", 153), []encoding.Language{encoding.Turkish},
+			GSM7National{Locking: encoding.Turkish, Single: encoding.Turkish}, 2},
+		// languages that can not represent the text are skipped.
+		{strings.Repeat("ı", 153), []encoding.Language{encoding.Spanish, encoding.Turkish},
+			GSM7National{Locking: encoding.Turkish, Single: encoding.Turkish}, 2},
+		{strings.Repeat("ı", 153), []encoding.Language{encoding.Spanish}, UCS2(""), 3},
+	}
+	for _, tc := range test {
+		p := NewPlan(tc.text, tc.langs...)
+		if len(p.Segments) != tc.segs {
+			t.Fatalf("%q: unexpected number of segments: want %d, have %d", tc.text, tc.segs, len(p.Segments))
+		}
+		switch want := tc.codec.(type) {
+		case GSM7National:
+			have, ok := p.Codec.(GSM7National)
+			if !ok || have.Locking != want.Locking || have.Single != want.Single {
+				t.Fatalf("%q: unexpected codec: %#v", tc.text, p.Codec)
+			}
+			var text string
+			for _, seg := range p.Segments {
+				text += string(seg.(GSM7National).Text)
+			}
+			if text != tc.text {
+				t.Fatalf("%q: segments do not add up to the text: %q", tc.text, text)
+			}
+		default:
+			if p.Codec.Type() != want.Type() {
+				t.Fatalf("%q: unexpected codec: %#v", tc.text, p.Codec)
+			}
+		}
+	}
+}
+
+func TestNewPlanWithHeader(t *testing.T) {
+	// 6 octets of port addressing information element.
+	test := []struct {
+		text string
+		segs []int
+	}{
+		{strings.Repeat("a", 152), []int{152}},
+		{strings.Repeat("a", 153), []int{145, 8}},
+		{strings.Repeat("ç", 66), []int{132}},
+		{strings.Repeat("ç", 67), []int{126, 8}},
+	}
+	for _, tc := range test {
+		p := NewPlanWithHeader(tc.text, 6)
+		var segs []int
+		for _, s := range p.Segments {
+			segs = append(segs, len(s.Encode()))
+		}
+		if !reflect.DeepEqual(segs, tc.segs) {
+			t.Fatalf("unexpected segments for %q: want %v, have %v", tc.text, tc.segs, segs)
+		}
+	}
+}
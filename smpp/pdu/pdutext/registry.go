@@ -0,0 +1,102 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"sync"
+)
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[DataCoding]func(text []byte) Codec{
+		DefaultType:   func(text []byte) Codec { return GSM7(text) },
+		IA5Type:       func(text []byte) Codec { return IA5(text) },
+		BinaryType:    func(text []byte) Codec { return Binary(text) },
+		Latin1Type:    func(text []byte) Codec { return Latin1(text) },
+		Binary2Type:   func(text []byte) Codec { return Binary2(text) },
+		JISType:       func(text []byte) Codec { return JIS(text) },
+		ISO88595Type:  func(text []byte) Codec { return ISO88595(text) },
+		ISO88598Type:  func(text []byte) Codec { return ISO88598(text) },
+		UCS2Type:      func(text []byte) Codec { return UCS2(text) },
+		ISO2022JPType: func(text []byte) Codec { return ISO2022JP(text) },
+		EXTJISType:    func(text []byte) Codec { return EXTJIS(text) },
+		KSC5601Type:   func(text []byte) Codec { return KSC5601(text) },
+	}
+)
+
+// Register sets the function that returns the codec of text received
+// with the given data_coding, replacing the built-in one, if any.
+//
+// This is mostly useful for SMSCs whose default alphabet is not GSM
+// 7-bit, e.g. Register(DefaultType, func(b []byte) Codec { return Latin1(b) }).
+func Register(dc DataCoding, f func(text []byte) Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[dc] = f
+}
+
+// NewCodec returns the codec of text received with the given
+// data_coding. Values of the GSM data coding scheme groups are resolved
+// to their alphabet, see Alphabet. Text of unknown or compressed data
+// codings is returned as Raw.
+func NewCodec(dc DataCoding, text []byte) Codec {
+	codecsMu.RLock()
+	f, ok := codecs[dc]
+	if !ok {
+		f, ok = codecs[dc.Alphabet()]
+	}
+	codecsMu.RUnlock()
+	if !ok {
+		return Raw(text)
+	}
+	return f(text)
+}
+
+// Alphabet returns the data_coding of the alphabet used by dc. Values
+// from 0x00 to 0x0F are SMPP data codings and are returned as is. Other
+// values are GSM 03.38 data coding schemes: the general and automatic
+// deletion groups (0x10 to 0x7F), the message waiting indication groups
+// (0xC0 to 0xEF) and the message class group (0xF0 to 0xFF), which use
+// the GSM 7-bit default alphabet, 8-bit data or UCS2. Reserved values and
+// compressed text are returned as is.
+func (dc DataCoding) Alphabet() DataCoding {
+	switch {
+	case dc < 0x10:
+		return dc
+	case dc < 0x80:
+		if dc&0x20 != 0 {
+			return dc // compressed
+		}
+		switch dc & 0x0c {
+		case 0x00:
+			return DefaultType
+		case 0x04:
+			return Binary2Type
+		case 0x08:
+			return UCS2Type
+		}
+	case dc >= 0xC0 && dc < 0xE0:
+		return DefaultType
+	case dc >= 0xE0 && dc < 0xF0:
+		return UCS2Type
+	case dc >= 0xF0:
+		if dc&0x04 != 0 {
+			return Binary2Type
+		}
+		return DefaultType
+	}
+	return dc
+}
+
+// Class returns the message class of dc and whether it has one. Class 0
+// is flash SMS, shown immediately and not stored by the handset, e.g.
+// data_coding 0x10 or 0xF0 for GSM 7-bit and 0x18 for UCS2.
+func (dc DataCoding) Class() (class uint8, ok bool) {
+	switch {
+	case dc >= 0x10 && dc < 0x80 && dc&0x10 != 0, dc >= 0xF0:
+		return uint8(dc & 0x03), true
+	}
+	return 0, false
+}
@@ -0,0 +1,82 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewCodec(t *testing.T) {
+	test := []struct {
+		dc   DataCoding
+		want Codec
+	}{
+		{DefaultType, GSM7("")},
+		{IA5Type, IA5("")},
+		{BinaryType, Binary("")},
+		{Latin1Type, Latin1("")},
+		{Binary2Type, Binary2("")},
+		{JISType, JIS("")},
+		{ISO88595Type, ISO88595("")},
+		{ISO88598Type, ISO88598("")},
+		{UCS2Type, UCS2("")},
+		{PictogramType, Raw("")},
+		{ISO2022JPType, ISO2022JP("")},
+		{EXTJISType, EXTJIS("")},
+		{KSC5601Type, KSC5601("")},
+		{0x10, GSM7("")},    // class 0, flash
+		{0x14, Binary2("")}, // class 0, 8-bit
+		{0x18, UCS2("")},    // class 0, UCS2
+		{0x30, Raw("")},     // compressed
+		{0x4b, UCS2("")},    // automatic deletion
+		{0x80, Raw("")},     // reserved
+		{0xc8, GSM7("")},    // message waiting, discard
+		{0xd8, GSM7("")},    // message waiting, store
+		{0xe8, UCS2("")},    // message waiting, store UCS2
+		{0xf0, GSM7("")},    // class 0, flash
+		{0xf5, Binary2("")}, // class 1, 8-bit
+	}
+	for _, tc := range test {
+		have := NewCodec(tc.dc, nil)
+		if reflect.TypeOf(have) != reflect.TypeOf(tc.want) {
+			t.Fatalf("unexpected codec for %#x: want %T, have %T", tc.dc, tc.want, have)
+		}
+	}
+}
+
+func TestDataCodingClass(t *testing.T) {
+	test := []struct {
+		dc    DataCoding
+		class uint8
+		ok    bool
+	}{
+		{DefaultType, 0, false},
+		{UCS2Type, 0, false},
+		{0x10, 0, true},
+		{0x19, 1, true},
+		{0x32, 2, true},
+		{0x40, 0, false},
+		{0x53, 3, true},
+		{0xc0, 0, false},
+		{0xf0, 0, true},
+		{0xf6, 2, true},
+	}
+	for _, tc := range test {
+		class, ok := tc.dc.Class()
+		if class != tc.class || ok != tc.ok {
+			t.Fatalf("unexpected class for %#x: want %d %t, have %d %t",
+				tc.dc, tc.class, tc.ok, class, ok)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer Register(DefaultType, func(text []byte) Codec { return GSM7(text) })
+	Register(DefaultType, func(text []byte) Codec { return Latin1(text) })
+	if c := NewCodec(0xf0, []byte("ol\xe1")); string(c.Decode()) != "olá" {
+		t.Fatalf("unexpected text: %q", c.Decode())
+	}
+}
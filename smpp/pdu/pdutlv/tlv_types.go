@@ -74,6 +74,31 @@ const (
 	TagAlertOnMessageDelivery   Tag = 0x130C
 	TagItsReplyType             Tag = 0x1380
 	TagItsSessionInfo           Tag = 0x1383
+	TagScInterfaceVersion       Tag = 0x0210
+)
+
+// Tag-Length-Value (TLV) tags introduced in SMPP 5.0.
+const (
+	TagCongestionState            Tag = 0x0428
+	TagBroadcastChannelIndicator  Tag = 0x0600
+	TagBroadcastContentType       Tag = 0x0601
+	TagBroadcastContentTypeInfo   Tag = 0x0602
+	TagBroadcastMessageClass      Tag = 0x0603
+	TagBroadcastRepNum            Tag = 0x0604
+	TagBroadcastFrequencyInterval Tag = 0x0605
+	TagBroadcastAreaIdentifier    Tag = 0x0606
+	TagBroadcastErrorStatus       Tag = 0x0607
+	TagBroadcastAreaSuccess       Tag = 0x0608
+	TagBroadcastEndTime           Tag = 0x0609
+	TagBroadcastServiceGroup      Tag = 0x060A
+	TagBillingIdentification      Tag = 0x060B
+	TagSourceNetworkID            Tag = 0x060D
+	TagDestNetworkID              Tag = 0x060E
+	TagSourceNodeID               Tag = 0x060F
+	TagDestNodeID                 Tag = 0x0610
+	TagDestAddrNpResolution       Tag = 0x0611
+	TagDestAddrNpInformation      Tag = 0x0612
+	TagDestAddrNpCountry          Tag = 0x0613
 )
 
 // Field is a PDU Tag-Length-Value (TLV) field
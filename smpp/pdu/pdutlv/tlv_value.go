@@ -0,0 +1,310 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutlv
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// TagMessageState is the message_state TLV, an alias of
+// TagMessageStateOption.
+const TagMessageState = TagMessageStateOption
+
+// Type is the data type of the value of a TLV.
+type Type int
+
+// Data types of TLV values.
+const (
+	TypeOctets           Type = iota // Octet string.
+	TypeUint8                        // 1 octet integer.
+	TypeUint16                       // 2 octet integer, big endian.
+	TypeUint32                       // 4 octet integer, big endian.
+	TypeCString                      // Null-terminated string.
+	TypeNetworkErrorCode             // Network type and 2 octet error code.
+)
+
+// spec is the data type and the minimum and maximum length of the
+// value of a TLV.
+type spec struct {
+	typ      Type
+	min, max int
+}
+
+// specs of the TLVs of SMPP 3.4 and 5.0, where 5.0 allows longer
+// values than 3.4 the longest is used.
+var specs = map[Tag]spec{
+	TagDestAddrSubunit:          {TypeUint8, 1, 1},
+	TagDestNetworkType:          {TypeUint8, 1, 1},
+	TagDestBearerType:           {TypeUint8, 1, 1},
+	TagDestTelematicsID:         {TypeUint16, 2, 2},
+	TagSourceAddrSubunit:        {TypeUint8, 1, 1},
+	TagSourceNetworkType:        {TypeUint8, 1, 1},
+	TagSourceBearerType:         {TypeUint8, 1, 1},
+	TagSourceTelematicsID:       {TypeUint16, 2, 2},
+	TagQosTimeToLive:            {TypeUint32, 4, 4},
+	TagPayloadType:              {TypeUint8, 1, 1},
+	TagAdditionalStatusInfoText: {TypeCString, 1, 256},
+	TagReceiptedMessageID:       {TypeCString, 1, 65},
+	TagMsMsgWaitFacilities:      {TypeUint8, 1, 1},
+	TagPrivacyIndicator:         {TypeUint8, 1, 1},
+	TagSourceSubaddress:         {TypeOctets, 2, 23},
+	TagDestSubaddress:           {TypeOctets, 2, 23},
+	TagUserMessageReference:     {TypeUint16, 2, 2},
+	TagUserResponseCode:         {TypeUint8, 1, 1},
+	TagSourcePort:               {TypeUint16, 2, 2},
+	TagDestinationPort:          {TypeUint16, 2, 2},
+	TagSarMsgRefNum:             {TypeUint16, 2, 2},
+	TagLanguageIndicator:        {TypeUint8, 1, 1},
+	TagSarTotalSegments:         {TypeUint8, 1, 1},
+	TagSarSegmentSeqnum:         {TypeUint8, 1, 1},
+	TagCallbackNumPresInd:       {TypeUint8, 1, 1},
+	TagCallbackNumAtag:          {TypeOctets, 0, 65},
+	TagNumberOfMessages:         {TypeUint8, 1, 1},
+	TagCallbackNum:              {TypeOctets, 4, 19},
+	TagDpfResult:                {TypeUint8, 1, 1},
+	TagSetDpf:                   {TypeUint8, 1, 1},
+	TagMsAvailabilityStatus:     {TypeUint8, 1, 1},
+	TagNetworkErrorCode:         {TypeNetworkErrorCode, 3, 3},
+	TagMessagePayload:           {TypeOctets, 0, 0xFFFF},
+	TagDeliveryFailureReason:    {TypeUint8, 1, 1},
+	TagMoreMessagesToSend:       {TypeUint8, 1, 1},
+	TagMessageStateOption:       {TypeUint8, 1, 1},
+	TagUssdServiceOp:            {TypeUint8, 1, 1},
+	TagDisplayTime:              {TypeUint8, 1, 1},
+	TagSmsSignal:                {TypeUint16, 2, 2},
+	TagMsValidity:               {TypeOctets, 1, 4}, // 1 octet in 3.4, up to 4 in 5.0.
+	TagAlertOnMessageDelivery:   {TypeOctets, 0, 1}, // empty in 3.4, optional octet in 5.0.
+	TagItsReplyType:             {TypeUint8, 1, 1},
+	TagItsSessionInfo:           {TypeOctets, 2, 2},
+	TagScInterfaceVersion:       {TypeUint8, 1, 1},
+
+	TagCongestionState:            {TypeUint8, 1, 1},
+	TagBroadcastChannelIndicator:  {TypeUint8, 1, 1},
+	TagBroadcastContentType:       {TypeOctets, 3, 3},
+	TagBroadcastContentTypeInfo:   {TypeOctets, 0, 255},
+	TagBroadcastMessageClass:      {TypeUint8, 1, 1},
+	TagBroadcastRepNum:            {TypeUint16, 2, 2},
+	TagBroadcastFrequencyInterval: {TypeOctets, 3, 3},
+	TagBroadcastAreaIdentifier:    {TypeOctets, 0, 100},
+	TagBroadcastErrorStatus:       {TypeUint32, 4, 4},
+	TagBroadcastAreaSuccess:       {TypeUint8, 1, 1},
+	TagBroadcastEndTime:           {TypeCString, 0, 17},
+	TagBroadcastServiceGroup:      {TypeOctets, 1, 255},
+	TagBillingIdentification:      {TypeOctets, 1, 1024},
+	TagSourceNetworkID:            {TypeCString, 7, 66},
+	TagDestNetworkID:              {TypeCString, 7, 66},
+	TagSourceNodeID:               {TypeOctets, 6, 6},
+	TagDestNodeID:                 {TypeOctets, 6, 6},
+	TagDestAddrNpResolution:       {TypeUint8, 1, 1},
+	TagDestAddrNpInformation:      {TypeOctets, 10, 10},
+	TagDestAddrNpCountry:          {TypeOctets, 1, 5},
+}
+
+// TypeOf returns the data type of the value of tag t, and whether the
+// tag is known.
+func TypeOf(t Tag) (Type, bool) {
+	s, ok := specs[t]
+	return s.typ, ok
+}
+
+// Validate returns an error if the length of value is not valid for
+// tag t. Values of unknown tags are always valid.
+func Validate(t Tag, value []byte) error {
+	s, ok := specs[t]
+	if !ok {
+		return nil
+	}
+	if l := len(value); l < s.min || l > s.max {
+		if s.min == s.max {
+			return fmt.Errorf("invalid length for TLV %#04x: want %d, have %d", uint16(t), s.min, l)
+		}
+		return fmt.Errorf("invalid length for TLV %#04x: want %d to %d, have %d", uint16(t), s.min, s.max, l)
+	}
+	return nil
+}
+
+// Validate returns an error for the first TLV of m with a value of
+// invalid length.
+func (m Map) Validate() error {
+	for t, v := range m {
+		if err := Validate(t, v.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NetworkErrorCode is the value of the network_error_code TLV.
+type NetworkErrorCode struct {
+	NetworkType uint8  // e.g. 1 for ANSI-136, 3 for GSM.
+	ErrorCode   uint16 // Network specific error code.
+}
+
+// NewUint8 returns the TLV t with a 1 octet integer value.
+func NewUint8(t Tag, v uint8) *Field {
+	return &Field{Tag: t, Data: []byte{v}}
+}
+
+// NewUint16 returns the TLV t with a 2 octet integer value.
+func NewUint16(t Tag, v uint16) *Field {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	return &Field{Tag: t, Data: b}
+}
+
+// NewUint32 returns the TLV t with a 4 octet integer value.
+func NewUint32(t Tag, v uint32) *Field {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return &Field{Tag: t, Data: b}
+}
+
+// NewCString returns the TLV t with the null-terminated string s.
+func NewCString(t Tag, s string) *Field {
+	return &Field{Tag: t, Data: append([]byte(s), 0x00)}
+}
+
+// NewOctets returns the TLV t with an octet string value.
+func NewOctets(t Tag, b []byte) *Field {
+	return &Field{Tag: t, Data: b}
+}
+
+// NewNetworkErrorCode returns the network_error_code TLV with value v.
+func NewNetworkErrorCode(v NetworkErrorCode) *Field {
+	return &Field{
+		Tag:  TagNetworkErrorCode,
+		Data: []byte{v.NetworkType, uint8(v.ErrorCode >> 8), uint8(v.ErrorCode)},
+	}
+}
+
+// Uint8 returns the value of t as a 1 octet integer.
+func (t *Field) Uint8() (uint8, error) {
+	if err := checkLen(t.Tag, t.Data, 1); err != nil {
+		return 0, err
+	}
+	return t.Data[0], nil
+}
+
+// Uint16 returns the value of t as a 2 octet integer.
+func (t *Field) Uint16() (uint16, error) {
+	if err := checkLen(t.Tag, t.Data, 2); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(t.Data), nil
+}
+
+// Uint32 returns the value of t as a 4 octet integer.
+func (t *Field) Uint32() (uint32, error) {
+	if err := checkLen(t.Tag, t.Data, 4); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(t.Data), nil
+}
+
+// CString returns the value of t as a string, without the null
+// terminator. Values that lack the terminator are accepted, since
+// some SMSCs do not send it.
+func (t *Field) CString() (string, error) {
+	if err := Validate(t.Tag, t.Data); err != nil && len(t.Data) > 0 {
+		return "", err
+	}
+	return t.String(), nil
+}
+
+// NetworkErrorCode returns the value of t as a network error code.
+func (t *Field) NetworkErrorCode() (NetworkErrorCode, error) {
+	if err := checkLen(t.Tag, t.Data, 3); err != nil {
+		return NetworkErrorCode{}, err
+	}
+	return NetworkErrorCode{
+		NetworkType: t.Data[0],
+		ErrorCode:   binary.BigEndian.Uint16(t.Data[1:]),
+	}, nil
+}
+
+// checkLen returns an error if value is not n octets long.
+func checkLen(t Tag, value []byte, n int) error {
+	if len(value) != n {
+		return fmt.Errorf("invalid length for TLV %#04x: want %d, have %d", uint16(t), n, len(value))
+	}
+	return nil
+}
+
+// field returns the TLV t of m as a Field, or nil.
+func (m Map) field(t Tag) *Field {
+	v, ok := m[t]
+	if !ok || v == nil {
+		return nil
+	}
+	if f, ok := v.(*Field); ok {
+		return f
+	}
+	return &Field{Tag: t, Data: v.Bytes()}
+}
+
+// Uint8 returns the value of the TLV t of m as a 1 octet integer, and
+// whether it is present and valid.
+func (m Map) Uint8(t Tag) (uint8, bool) {
+	f := m.field(t)
+	if f == nil {
+		return 0, false
+	}
+	v, err := f.Uint8()
+	return v, err == nil
+}
+
+// Uint16 returns the value of the TLV t of m as a 2 octet integer, and
+// whether it is present and valid.
+func (m Map) Uint16(t Tag) (uint16, bool) {
+	f := m.field(t)
+	if f == nil {
+		return 0, false
+	}
+	v, err := f.Uint16()
+	return v, err == nil
+}
+
+// Uint32 returns the value of the TLV t of m as a 4 octet integer, and
+// whether it is present and valid.
+func (m Map) Uint32(t Tag) (uint32, bool) {
+	f := m.field(t)
+	if f == nil {
+		return 0, false
+	}
+	v, err := f.Uint32()
+	return v, err == nil
+}
+
+// CString returns the value of the TLV t of m as a string, and whether
+// it is present and valid.
+func (m Map) CString(t Tag) (string, bool) {
+	f := m.field(t)
+	if f == nil {
+		return "", false
+	}
+	v, err := f.CString()
+	return v, err == nil
+}
+
+// Octets returns the value of the TLV t of m, and whether it is present.
+func (m Map) Octets(t Tag) ([]byte, bool) {
+	f := m.field(t)
+	if f == nil {
+		return nil, false
+	}
+	return f.Data, true
+}
+
+// NetworkErrorCode returns the value of the network_error_code TLV of
+// m, and whether it is present and valid.
+func (m Map) NetworkErrorCode() (NetworkErrorCode, bool) {
+	f := m.field(TagNetworkErrorCode)
+	if f == nil {
+		return NetworkErrorCode{}, false
+	}
+	v, err := f.NetworkErrorCode()
+	return v, err == nil
+}
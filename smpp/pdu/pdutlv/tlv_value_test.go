@@ -0,0 +1,84 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutlv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTypedValues(t *testing.T) {
+	m := make(Map)
+	m.Set(TagSarTotalSegments, NewUint8(TagSarTotalSegments, 3))
+	m.Set(TagSarMsgRefNum, NewUint16(TagSarMsgRefNum, 0x1234))
+	m.Set(TagQosTimeToLive, NewUint32(TagQosTimeToLive, 0x01020304))
+	m.Set(TagReceiptedMessageID, NewCString(TagReceiptedMessageID, "foobar"))
+	m.Set(TagNetworkErrorCode, NewNetworkErrorCode(NetworkErrorCode{NetworkType: 3, ErrorCode: 0x0102}))
+	m.Set(TagMessageState, []byte{2, 3}) // invalid length
+	if v, ok := m.Uint8(TagSarTotalSegments); !ok || v != 3 {
+		t.Fatalf("unexpected uint8: %d %t", v, ok)
+	}
+	if v, ok := m.Uint16(TagSarMsgRefNum); !ok || v != 0x1234 {
+		t.Fatalf("unexpected uint16: %#x %t", v, ok)
+	}
+	if v, ok := m.Uint32(TagQosTimeToLive); !ok || v != 0x01020304 {
+		t.Fatalf("unexpected uint32: %#x %t", v, ok)
+	}
+	if v, ok := m.CString(TagReceiptedMessageID); !ok || v != "foobar" {
+		t.Fatalf("unexpected string: %q %t", v, ok)
+	}
+	if v, ok := m.NetworkErrorCode(); !ok || v.NetworkType != 3 || v.ErrorCode != 0x0102 {
+		t.Fatalf("unexpected network error code: %#v %t", v, ok)
+	}
+	if b := m[TagNetworkErrorCode].Bytes(); !bytes.Equal(b, []byte{3, 1, 2}) {
+		t.Fatalf("unexpected network error code: %x", b)
+	}
+	if v, ok := m.Octets(TagReceiptedMessageID); !ok || !bytes.Equal(v, []byte("foobar\x00")) {
+		t.Fatalf("unexpected octets: %q %t", v, ok)
+	}
+	if _, ok := m.Uint8(TagMessageState); ok {
+		t.Fatal("unexpected uint8 of invalid length")
+	}
+	if _, ok := m.Uint8(TagDpfResult); ok {
+		t.Fatal("unexpected uint8 of missing TLV")
+	}
+	if _, ok := m.Uint16(TagSarTotalSegments); ok {
+		t.Fatal("unexpected uint16 of 1 octet TLV")
+	}
+	if err := m.Validate(); err == nil {
+		t.Fatal("unexpected valid map")
+	}
+	delete(m, TagMessageState)
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	test := []struct {
+		tag   Tag
+		value []byte
+		ok    bool
+	}{
+		{TagDestAddrSubunit, []byte{1}, true},
+		{TagDestAddrSubunit, nil, false},
+		{TagSourceTelematicsID, []byte{0, 1}, true},
+		{TagReceiptedMessageID, bytes.Repeat([]byte("1"), 65), true},
+		{TagReceiptedMessageID, bytes.Repeat([]byte("1"), 66), false},
+		{TagCallbackNum, []byte{0, 1, 1}, false},
+		{TagAlertOnMessageDelivery, nil, true},
+		{TagAlertOnMessageDelivery, []byte{1}, true},
+		{TagMsValidity, []byte{1, 2, 3, 4}, true},
+		{Tag(0x1400), bytes.Repeat([]byte("1"), 1000), true},
+	}
+	for _, tc := range test {
+		if err := Validate(tc.tag, tc.value); (err == nil) != tc.ok {
+			t.Fatalf("unexpected validation of %#04x with %d octets: %v", uint16(tc.tag), len(tc.value), err)
+		}
+	}
+	if typ, ok := TypeOf(TagNetworkErrorCode); !ok || typ != TypeNetworkErrorCode {
+		t.Fatalf("unexpected type: %d %t", typ, ok)
+	}
+}
@@ -5,8 +5,8 @@
 package pdu
 
 import (
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
 )
 
 // PDU Types.
@@ -40,6 +40,16 @@ const (
 	DataSMRespID          ID = 0x80000103
 )
 
+// PDU Types introduced in SMPP 5.0.
+const (
+	BroadcastSMID           ID = 0x00000111
+	BroadcastSMRespID       ID = 0x80000111
+	QueryBroadcastSMID      ID = 0x00000112
+	QueryBroadcastSMRespID  ID = 0x80000112
+	CancelBroadcastSMID     ID = 0x00000113
+	CancelBroadcastSMRespID ID = 0x80000113
+)
+
 // GenericNACK PDU.
 type GenericNACK struct{ *codec }
 
@@ -374,6 +384,13 @@ func NewUnbindResp() Body {
 	return b
 }
 
+// NewUnbindRespSeq creates and initializes a UnbindResp PDU for a specific seq.
+func NewUnbindRespSeq(seq uint32) Body {
+	b := newUnbindResp(&Header{ID: UnbindRespID, Seq: seq})
+	b.init()
+	return b
+}
+
 // EnquireLink PDU.
 type EnquireLink struct{ *codec }
 
@@ -408,3 +425,352 @@ func NewEnquireLinkRespSeq(seq uint32) Body {
 	b.init()
 	return b
 }
+
+// CancelSM PDU.
+type CancelSM struct{ *codec }
+
+func newCancelSM(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.ServiceType,
+			pdufield.MessageID,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.DestAddrTON,
+			pdufield.DestAddrNPI,
+			pdufield.DestinationAddr,
+		},
+	}
+}
+
+// NewCancelSM creates and initializes a new CancelSM PDU.
+func NewCancelSM() Body {
+	b := newCancelSM(&Header{ID: CancelSMID})
+	b.init()
+	return b
+}
+
+// CancelSMResp PDU.
+type CancelSMResp struct{ *codec }
+
+func newCancelSMResp(hdr *Header) *codec {
+	return &codec{h: hdr}
+}
+
+// NewCancelSMResp creates and initializes a new CancelSMResp PDU.
+func NewCancelSMResp() Body {
+	b := newCancelSMResp(&Header{ID: CancelSMRespID})
+	b.init()
+	return b
+}
+
+// NewCancelSMRespSeq creates and initializes a new CancelSMResp PDU for a specific seq.
+func NewCancelSMRespSeq(seq uint32) Body {
+	b := newCancelSMResp(&Header{ID: CancelSMRespID, Seq: seq})
+	b.init()
+	return b
+}
+
+// ReplaceSM PDU.
+type ReplaceSM struct{ *codec }
+
+func newReplaceSM(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.MessageID,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.ScheduleDeliveryTime,
+			pdufield.ValidityPeriod,
+			pdufield.RegisteredDelivery,
+			pdufield.SMDefaultMsgID,
+			pdufield.SMLength,
+			pdufield.ShortMessage,
+		},
+	}
+}
+
+// NewReplaceSM creates and initializes a new ReplaceSM PDU.
+func NewReplaceSM() Body {
+	b := newReplaceSM(&Header{ID: ReplaceSMID})
+	b.init()
+	return b
+}
+
+// ReplaceSMResp PDU.
+type ReplaceSMResp struct{ *codec }
+
+func newReplaceSMResp(hdr *Header) *codec {
+	return &codec{h: hdr}
+}
+
+// NewReplaceSMResp creates and initializes a new ReplaceSMResp PDU.
+func NewReplaceSMResp() Body {
+	b := newReplaceSMResp(&Header{ID: ReplaceSMRespID})
+	b.init()
+	return b
+}
+
+// NewReplaceSMRespSeq creates and initializes a new ReplaceSMResp PDU for a specific seq.
+func NewReplaceSMRespSeq(seq uint32) Body {
+	b := newReplaceSMResp(&Header{ID: ReplaceSMRespID, Seq: seq})
+	b.init()
+	return b
+}
+
+// DataSM PDU.
+type DataSM struct{ *codec }
+
+func newDataSM(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.ServiceType,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.DestAddrTON,
+			pdufield.DestAddrNPI,
+			pdufield.DestinationAddr,
+			pdufield.ESMClass,
+			pdufield.RegisteredDelivery,
+			pdufield.DataCoding,
+		},
+	}
+}
+
+// NewDataSM creates and initializes a new DataSM PDU.
+func NewDataSM(fields pdutlv.Fields) Body {
+	b := newDataSM(&Header{ID: DataSMID})
+	b.init()
+	for tag, value := range fields {
+		b.t.Set(tag, value)
+	}
+	return b
+}
+
+// DataSMResp PDU.
+type DataSMResp struct{ *codec }
+
+func newDataSMResp(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.MessageID,
+		},
+	}
+}
+
+// NewDataSMResp creates and initializes a new DataSMResp PDU.
+func NewDataSMResp() Body {
+	b := newDataSMResp(&Header{ID: DataSMRespID})
+	b.init()
+	return b
+}
+
+// NewDataSMRespSeq creates and initializes a new DataSMResp PDU for a specific seq.
+func NewDataSMRespSeq(seq uint32) Body {
+	b := newDataSMResp(&Header{ID: DataSMRespID, Seq: seq})
+	b.init()
+	return b
+}
+
+// Outbind PDU.
+type Outbind struct{ *codec }
+
+func newOutbind(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.SystemID,
+			pdufield.Password,
+		},
+	}
+}
+
+// NewOutbind creates and initializes a new Outbind PDU.
+func NewOutbind() Body {
+	b := newOutbind(&Header{ID: OutbindID})
+	b.init()
+	return b
+}
+
+// AlertNotification PDU.
+type AlertNotification struct{ *codec }
+
+func newAlertNotification(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.EsmeAddrTON,
+			pdufield.EsmeAddrNPI,
+			pdufield.EsmeAddr,
+		},
+	}
+}
+
+// NewAlertNotification creates and initializes a new AlertNotification PDU.
+func NewAlertNotification() Body {
+	b := newAlertNotification(&Header{ID: AlertNotificationID})
+	b.init()
+	return b
+}
+
+// BroadcastSM PDU, of SMPP 5.0.
+type BroadcastSM struct{ *codec }
+
+func newBroadcastSM(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.ServiceType,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.MessageID,
+			pdufield.PriorityFlag,
+			pdufield.ScheduleDeliveryTime,
+			pdufield.ValidityPeriod,
+			pdufield.ReplaceIfPresentFlag,
+			pdufield.DataCoding,
+			pdufield.SMDefaultMsgID,
+		},
+	}
+}
+
+// NewBroadcastSM creates and initializes a new BroadcastSM PDU.
+func NewBroadcastSM(fields pdutlv.Fields) Body {
+	b := newBroadcastSM(&Header{ID: BroadcastSMID})
+	b.init()
+	for tag, value := range fields {
+		b.t.Set(tag, value)
+	}
+	return b
+}
+
+// BroadcastSMResp PDU, of SMPP 5.0.
+type BroadcastSMResp struct{ *codec }
+
+func newBroadcastSMResp(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.MessageID,
+		},
+	}
+}
+
+// NewBroadcastSMResp creates and initializes a new BroadcastSMResp PDU.
+func NewBroadcastSMResp() Body {
+	b := newBroadcastSMResp(&Header{ID: BroadcastSMRespID})
+	b.init()
+	return b
+}
+
+// NewBroadcastSMRespSeq creates and initializes a new BroadcastSMResp PDU for a specific seq.
+func NewBroadcastSMRespSeq(seq uint32) Body {
+	b := newBroadcastSMResp(&Header{ID: BroadcastSMRespID, Seq: seq})
+	b.init()
+	return b
+}
+
+// QueryBroadcastSM PDU, of SMPP 5.0.
+type QueryBroadcastSM struct{ *codec }
+
+func newQueryBroadcastSM(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.MessageID,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+		},
+	}
+}
+
+// NewQueryBroadcastSM creates and initializes a new QueryBroadcastSM PDU.
+func NewQueryBroadcastSM() Body {
+	b := newQueryBroadcastSM(&Header{ID: QueryBroadcastSMID})
+	b.init()
+	return b
+}
+
+// QueryBroadcastSMResp PDU, of SMPP 5.0. The state of the broadcast
+// is in the message_state, broadcast_area_identifier and
+// broadcast_area_success TLVs.
+type QueryBroadcastSMResp struct{ *codec }
+
+func newQueryBroadcastSMResp(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.MessageID,
+		},
+	}
+}
+
+// NewQueryBroadcastSMResp creates and initializes a new QueryBroadcastSMResp PDU.
+func NewQueryBroadcastSMResp() Body {
+	b := newQueryBroadcastSMResp(&Header{ID: QueryBroadcastSMRespID})
+	b.init()
+	return b
+}
+
+// NewQueryBroadcastSMRespSeq creates and initializes a new QueryBroadcastSMResp PDU for a specific seq.
+func NewQueryBroadcastSMRespSeq(seq uint32) Body {
+	b := newQueryBroadcastSMResp(&Header{ID: QueryBroadcastSMRespID, Seq: seq})
+	b.init()
+	return b
+}
+
+// CancelBroadcastSM PDU, of SMPP 5.0.
+type CancelBroadcastSM struct{ *codec }
+
+func newCancelBroadcastSM(hdr *Header) *codec {
+	return &codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.ServiceType,
+			pdufield.MessageID,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+		},
+	}
+}
+
+// NewCancelBroadcastSM creates and initializes a new CancelBroadcastSM PDU.
+func NewCancelBroadcastSM() Body {
+	b := newCancelBroadcastSM(&Header{ID: CancelBroadcastSMID})
+	b.init()
+	return b
+}
+
+// CancelBroadcastSMResp PDU, of SMPP 5.0.
+type CancelBroadcastSMResp struct{ *codec }
+
+func newCancelBroadcastSMResp(hdr *Header) *codec {
+	return &codec{h: hdr}
+}
+
+// NewCancelBroadcastSMResp creates and initializes a new CancelBroadcastSMResp PDU.
+func NewCancelBroadcastSMResp() Body {
+	b := newCancelBroadcastSMResp(&Header{ID: CancelBroadcastSMRespID})
+	b.init()
+	return b
+}
+
+// NewCancelBroadcastSMRespSeq creates and initializes a new CancelBroadcastSMResp PDU for a specific seq.
+func NewCancelBroadcastSMRespSeq(seq uint32) Body {
+	b := newCancelBroadcastSMResp(&Header{ID: CancelBroadcastSMRespID, Seq: seq})
+	b.init()
+	return b
+}
@@ -10,7 +10,8 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
 )
 
 func TestBind(t *testing.T) {
@@ -82,3 +83,207 @@ func TestBindResp(t *testing.T) {
 	t.Log(tx)
 }
 */
+
+func TestCancelSM(t *testing.T) {
+	tx := []byte{
+		0x00, 0x00, 0x00, 0x23, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+		0x00, 0x31, 0x33, 0x00, 0x05, 0x00, 0x72, 0x6F,
+		0x6F, 0x74, 0x00, 0x01, 0x01, 0x31, 0x32, 0x33,
+		0x34, 0x35, 0x00,
+	}
+	pdu := NewCancelSM()
+	f := pdu.Fields()
+	f.Set(pdufield.MessageID, "13")
+	f.Set(pdufield.SourceAddrTON, 5)
+	f.Set(pdufield.SourceAddr, "root")
+	f.Set(pdufield.DestAddrTON, 1)
+	f.Set(pdufield.DestAddrNPI, 1)
+	f.Set(pdufield.DestinationAddr, "12345")
+	pdu.Header().Seq = 2
+	var b bytes.Buffer
+	if err := pdu.SerializeTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tx, b.Bytes()) {
+		t.Fatalf("unexpected bytes:\nwant:\n%s\nhave:\n%s",
+			hex.Dump(tx), hex.Dump(b.Bytes()))
+	}
+	pdu, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := pdu.Header(); h.ID != CancelSMID || h.Seq != 2 {
+		t.Fatalf("unexpected header: %#v", h)
+	}
+	test := []struct {
+		n pdufield.Name
+		v string
+	}{
+		{pdufield.MessageID, "13"},
+		{pdufield.SourceAddrTON, "5"},
+		{pdufield.SourceAddr, "root"},
+		{pdufield.DestinationAddr, "12345"},
+	}
+	for _, el := range test {
+		f := pdu.Fields()[el.n]
+		if f == nil {
+			t.Fatalf("missing field: %s", el.n)
+		}
+		if f.String() != el.v {
+			t.Fatalf("unexpected value for %q: want %q, have %q",
+				el.n, el.v, f.String())
+		}
+	}
+}
+
+func TestReplaceSM(t *testing.T) {
+	pdu := NewReplaceSM()
+	f := pdu.Fields()
+	f.Set(pdufield.MessageID, "13")
+	f.Set(pdufield.SourceAddr, "root")
+	f.Set(pdufield.RegisteredDelivery, 1)
+	f.Set(pdufield.ShortMessage, "hello world")
+	var b bytes.Buffer
+	if err := pdu.SerializeTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	pdu, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := pdu.Header(); h.ID != ReplaceSMID {
+		t.Fatalf("unexpected ID: want %s, have %s", ReplaceSMID, h.ID)
+	}
+	test := []struct {
+		n pdufield.Name
+		v string
+	}{
+		{pdufield.MessageID, "13"},
+		{pdufield.SourceAddr, "root"},
+		{pdufield.RegisteredDelivery, "1"},
+		{pdufield.SMLength, "11"},
+		{pdufield.ShortMessage, "hello world"},
+	}
+	for _, el := range test {
+		f := pdu.Fields()[el.n]
+		if f == nil {
+			t.Fatalf("missing field: %s", el.n)
+		}
+		if f.String() != el.v {
+			t.Fatalf("unexpected value for %q: want %q, have %q",
+				el.n, el.v, f.String())
+		}
+	}
+	for _, resp := range []Body{NewCancelSMRespSeq(7), NewReplaceSMRespSeq(7)} {
+		b.Reset()
+		if err := resp.SerializeTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		p, err := Decode(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *p.Header() != *resp.Header() {
+			t.Fatalf("unexpected header: want %#v, have %#v",
+				resp.Header(), p.Header())
+		}
+	}
+}
+
+func TestAlertNotification(t *testing.T) {
+	tx := []byte{
+		0x00, 0x00, 0x00, 0x24, 0x00, 0x00, 0x01, 0x02,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0x01, 0x01, 0x31, 0x32, 0x33, 0x34, 0x35, 0x00,
+		0x00, 0x00, 0x72, 0x6F, 0x6F, 0x74, 0x00, 0x04,
+		0x22, 0x00, 0x01, 0x02,
+	}
+	pdu, err := Decode(bytes.NewBuffer(tx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := pdu.Header().ID; id != AlertNotificationID {
+		t.Fatalf("unexpected ID: want %s, have %s", AlertNotificationID, id)
+	}
+	test := []struct {
+		n pdufield.Name
+		v string
+	}{
+		{pdufield.SourceAddrTON, "1"},
+		{pdufield.SourceAddrNPI, "1"},
+		{pdufield.SourceAddr, "12345"},
+		{pdufield.EsmeAddr, "root"},
+	}
+	for _, el := range test {
+		f := pdu.Fields()[el.n]
+		if f == nil {
+			t.Fatalf("missing field: %s", el.n)
+		}
+		if f.String() != el.v {
+			t.Fatalf("unexpected value for %q: want %q, have %q",
+				el.n, el.v, f.String())
+		}
+	}
+	ms := pdu.TLVFields()[pdutlv.TagMsAvailabilityStatus]
+	if ms == nil || !bytes.Equal(ms.Bytes(), []byte{0x02}) {
+		t.Fatalf("unexpected ms_availability_status: %#v", ms)
+	}
+}
+
+func TestBroadcastSM(t *testing.T) {
+	tx := []byte{
+		0x00, 0x00, 0x00, 0x25, 0x00, 0x00, 0x01, 0x11,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03,
+		0x00, 0x01, 0x01, 0x72, 0x6F, 0x6F, 0x74, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x00, 0x06,
+		0x04, 0x00, 0x02, 0x00, 0x03,
+	}
+	pdu := NewBroadcastSM(pdutlv.Fields{
+		pdutlv.TagBroadcastRepNum: pdutlv.NewUint16(pdutlv.TagBroadcastRepNum, 3),
+	})
+	f := pdu.Fields()
+	f.Set(pdufield.SourceAddrTON, 1)
+	f.Set(pdufield.SourceAddrNPI, 1)
+	f.Set(pdufield.SourceAddr, "root")
+	f.Set(pdufield.DataCoding, 8)
+	pdu.Header().Seq = 3
+	var b bytes.Buffer
+	if err := pdu.SerializeTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tx, b.Bytes()) {
+		t.Fatalf("unexpected bytes:\nwant:\n%s\nhave:\n%s",
+			hex.Dump(tx), hex.Dump(b.Bytes()))
+	}
+	pdu, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := pdu.Header(); h.ID != BroadcastSMID || h.Seq != 3 {
+		t.Fatalf("unexpected header: %#v", h)
+	}
+	if v := pdu.Fields()[pdufield.SourceAddr]; v == nil || v.String() != "root" {
+		t.Fatalf("unexpected source_addr: %v", v)
+	}
+	if n, ok := pdu.TLVFields().Uint16(pdutlv.TagBroadcastRepNum); !ok || n != 3 {
+		t.Fatalf("unexpected broadcast_rep_num: %d", n)
+	}
+}
+
+func TestCancelBroadcastSMResp(t *testing.T) {
+	tx := []byte{
+		0x00, 0x00, 0x00, 0x10, 0x80, 0x00, 0x01, 0x13,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04,
+	}
+	pdu, err := Decode(bytes.NewReader(tx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := pdu.Header(); h.ID != CancelBroadcastSMRespID || h.Seq != 4 {
+		t.Fatalf("unexpected header: %#v", h)
+	}
+	if s := pdu.Header().ID.String(); s != "CancelBroadcastSMResp" {
+		t.Fatalf("unexpected name: %q", s)
+	}
+}
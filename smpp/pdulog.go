@@ -0,0 +1,129 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+)
+
+// PDULogger is connection middleware that logs the PDUs read from and
+// written to the connection, decoded, with passwords redacted. Use its
+// Wrap method as the WrapConn of a Transmitter, Transceiver or
+// Receiver.
+type PDULogger struct {
+	// Logger receives the log lines, the standard logger if nil.
+	Logger interface {
+		Printf(format string, v ...interface{})
+	}
+
+	// HexDump adds a hex dump of every PDU to the log.
+	HexDump bool
+}
+
+// Wrap returns c wrapped by the logger.
+func (pl *PDULogger) Wrap(c Conn) Conn {
+	return &logConn{Conn: c, pl: pl}
+}
+
+// logConn is a Conn that logs PDUs.
+type logConn struct {
+	Conn
+	pl *PDULogger
+}
+
+// Read implements the Conn interface.
+func (lc *logConn) Read() (pdu.Body, error) {
+	p, err := lc.Conn.Read()
+	if err == nil {
+		lc.pl.log("<", p)
+	}
+	return p, err
+}
+
+// Write implements the Conn interface.
+func (lc *logConn) Write(p pdu.Body) error {
+	return lc.WriteContext(context.Background(), p)
+}
+
+// WriteContext implements the ContextWriter interface.
+func (lc *logConn) WriteContext(ctx context.Context, p pdu.Body) error {
+	lc.pl.log(">", p)
+	return WriteContext(ctx, lc.Conn, p)
+}
+
+// redacted replaces passwords in logs.
+const redacted = "********"
+
+// log logs p, read from the connection if dir is "<", or written to
+// it if dir is ">".
+func (pl *PDULogger) log(dir string, p pdu.Body) {
+	var b strings.Builder
+	h := p.Header()
+	fmt.Fprintf(&b, "smpp: %s %s seq=%d status=%#x", dir, h.ID, h.Seq, uint32(h.Status))
+	f := p.Fields()
+	for _, k := range p.FieldList() {
+		v := f[k]
+		switch {
+		case v == nil:
+			continue
+		case k == pdufield.Password:
+			fmt.Fprintf(&b, " %s=%s", k, redacted)
+		case k == pdufield.ShortMessage:
+			fmt.Fprintf(&b, " %s=%q", k, v.Bytes())
+		default:
+			fmt.Fprintf(&b, " %s=%q", k, v.String())
+		}
+	}
+	tlv := p.TLVFields()
+	tags := make([]int, 0, len(tlv))
+	for t := range tlv {
+		tags = append(tags, int(t))
+	}
+	sort.Ints(tags)
+	for _, t := range tags {
+		fmt.Fprintf(&b, " tlv%#04x=%x", t, tlv[pdutlv.Tag(t)].Bytes())
+	}
+	if pl.HexDump {
+		b.WriteString("\n")
+		b.WriteString(strings.TrimSuffix(hex.Dump(serializeRedacted(p)), "\n"))
+	}
+	if pl.Logger != nil {
+		pl.Logger.Printf("%s", b.String())
+	} else {
+		log.Printf("%s", b.String())
+	}
+}
+
+// serializeRedacted returns the binary data of p, with the password
+// replaced if any.
+func serializeRedacted(p pdu.Body) []byte {
+	var b bytes.Buffer
+	if err := p.SerializeTo(&b); err != nil {
+		return nil
+	}
+	if pw := p.Fields()[pdufield.Password]; pw == nil || len(pw.Bytes()) == 0 {
+		return b.Bytes()
+	}
+	c, err := pdu.Decode(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		return nil
+	}
+	c.Fields().Set(pdufield.Password, redacted)
+	b.Reset()
+	if err := c.SerializeTo(&b); err != nil {
+		return nil
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+)
+
+type testLogger struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	fmt.Fprintf(&l.b, format+"\n", v...)
+	l.mu.Unlock()
+}
+
+func (l *testLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.String()
+}
+
+func TestPDULogger(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	l := &testLogger{}
+	var wrapped int
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+		WrapConn: ChainConn(
+			(&PDULogger{Logger: l, HexDump: true}).Wrap,
+			func(c Conn) Conn { wrapped++; return c },
+		),
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	tx.Submit(&ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")})
+	if wrapped != 1 {
+		t.Fatalf("unexpected number of wrapped connections: %d", wrapped)
+	}
+	out := l.String()
+	if strings.Contains(out, smpptest.DefaultPasswd) ||
+		strings.Contains(out, fmt.Sprintf("%x", smpptest.DefaultPasswd)[:8]) {
+		t.Fatalf("password not redacted:\n%s", out)
+	}
+	for _, want := range []string{
+		"smpp: > " + pdu.BindTransmitterID.String() + " seq=",
+		`password=********`,
+		`system_id="` + smpptest.DefaultUser + `"`,
+		"smpp: < " + pdu.BindTransmitterRespID.String(),
+		`short_message="Lorem ipsum"`,
+		"|client.********.|",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in log:\n%s", want, out)
+		}
+	}
+}
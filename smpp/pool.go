@@ -0,0 +1,205 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"sync"
+)
+
+// Submitter is a client connection that sends short messages, as used
+// by Pool. It is implemented by Transmitter and Transceiver.
+type Submitter interface {
+	ClientConn
+	SubmitContext(ctx context.Context, sm *ShortMessage) (*ShortMessage, error)
+	WindowStatus() WindowStatus
+}
+
+// PoolConnStatus is the status change of a client of a Pool, as sent
+// on the channel returned by Pool.Bind.
+type PoolConnStatus struct {
+	ConnStatus
+	Index  int       // Index of the client in Pool.Clients.
+	Client Submitter // The client.
+}
+
+// Pool distributes short messages over multiple binds, for SMSCs that
+// cap the throughput of each bind. The clients may connect to several
+// addresses for failover.
+//
+// Each message is sent by the connected client with the fewest
+// requests waiting for a response. Clients leave the pool when they
+// lose their connection and rejoin it when they bind again.
+//
+// The pool tracks the status of Transmitter and Transceiver clients
+// through their ConnStatusHandler, which it chains to the one set, if
+// any. Clients must not be bound before the pool.
+type Pool struct {
+	Clients []Submitter
+
+	// ConnStatusHandler is called with a *PoolConnStatus on every
+	// status change of the clients, one at a time, optional. Like
+	// that of a client, it must not block; see ConnStatusHandlerFunc.
+	ConnStatusHandler ConnStatusHandlerFunc
+
+	mu    sync.Mutex
+	up    []bool
+	next  int // first client to consider, for round robin on ties.
+	once  sync.Once
+	state chan ConnStatus
+	hmu   sync.Mutex // serializes calls to ConnStatusHandler.
+}
+
+// NewPool returns a Pool of the given clients.
+func NewPool(clients ...Submitter) *Pool {
+	return &Pool{Clients: clients}
+}
+
+// Bind binds all clients of the pool and returns a channel with the
+// status changes of all of them, of type *PoolConnStatus. Changes are
+// dropped if the channel is not drained; use ConnStatusHandler to get
+// every change.
+//
+// Bind implements the ClientConn interface.
+func (p *Pool) Bind() <-chan ConnStatus {
+	p.once.Do(func() {
+		p.state = make(chan ConnStatus, len(p.Clients))
+		p.up = make([]bool, len(p.Clients))
+		var wg sync.WaitGroup
+		for i, c := range p.Clients {
+			i, c := i, c
+			hooked := hookStatus(c, func(st ConnStatus) { p.update(i, c, st) })
+			wg.Add(1)
+			go func(status <-chan ConnStatus) {
+				defer wg.Done()
+				for st := range status {
+					if !hooked {
+						p.update(i, c, st)
+					}
+				}
+			}(c.Bind())
+		}
+		go func() {
+			wg.Wait()
+			close(p.state)
+		}()
+	})
+	return p.state
+}
+
+// hookStatus chains h to the ConnStatusHandler of c, if c is a
+// Transmitter or Transceiver, and reports whether it did.
+func hookStatus(c Submitter, h ConnStatusHandlerFunc) bool {
+	var hp *ConnStatusHandlerFunc
+	switch c := c.(type) {
+	case *Transmitter:
+		hp = &c.ConnStatusHandler
+	case *Transceiver:
+		hp = &c.ConnStatusHandler
+	default:
+		return false
+	}
+	if prev := *hp; prev != nil {
+		*hp = func(st ConnStatus) {
+			prev(st)
+			h(st)
+		}
+	} else {
+		*hp = h
+	}
+	return true
+}
+
+// update records the status change st of client i.
+func (p *Pool) update(i int, c Submitter, st ConnStatus) {
+	p.mu.Lock()
+	p.up[i] = st.Status() == Connected
+	p.mu.Unlock()
+	ps := &PoolConnStatus{ConnStatus: st, Index: i, Client: c}
+	if p.ConnStatusHandler != nil {
+		p.hmu.Lock()
+		p.ConnStatusHandler(ps)
+		p.hmu.Unlock()
+	}
+	select {
+	case p.state <- ps:
+	default:
+	}
+}
+
+// Close closes all clients of the pool.
+//
+// Close implements the ClientConn interface.
+func (p *Pool) Close() error {
+	var err error
+	for _, c := range p.Clients {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Len returns the number of connected clients.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, up := range p.up {
+		if up {
+			n++
+		}
+	}
+	return n
+}
+
+// Submit sends a short message using the connected client with the
+// fewest requests in flight. It returns ErrNotConnected if no client
+// is connected.
+func (p *Pool) Submit(sm *ShortMessage) (*ShortMessage, error) {
+	return p.SubmitContext(context.Background(), sm)
+}
+
+// SubmitContext is like Submit but honours the deadline and
+// cancellation of ctx, returning ctx.Err() when ctx is done first.
+//
+// If the client picked has lost its connection, the message is sent
+// by the next one.
+func (p *Pool) SubmitContext(ctx context.Context, sm *ShortMessage) (*ShortMessage, error) {
+	tried := make([]bool, len(p.Clients))
+	for {
+		i := p.pick(tried)
+		if i < 0 {
+			return nil, ErrNotConnected
+		}
+		resp, err := p.Clients[i].SubmitContext(ctx, sm)
+		if err != ErrNotConnected {
+			return resp, err
+		}
+		tried[i] = true
+	}
+}
+
+// pick returns the index of the connected client with the fewest
+// requests in flight, skipping the ones tried, or -1.
+func (p *Pool) pick(tried []bool) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	best, min := -1, 0
+	for n := range p.up {
+		i := (p.next + n) % len(p.up)
+		if !p.up[i] || tried[i] {
+			continue
+		}
+		inflight := p.Clients[i].WindowStatus().Inflight
+		if best < 0 || inflight < min {
+			best, min = i, inflight
+		}
+	}
+	if best >= 0 {
+		p.next = (best + 1) % len(p.up)
+	}
+	return best
+}
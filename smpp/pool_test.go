@@ -0,0 +1,119 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+)
+
+func TestPool(t *testing.T) {
+	var count [2]int32
+	var down int32
+	var servers [2]*smpptest.Server
+	var clients []Submitter
+	for i := range servers {
+		i := i
+		s := smpptest.NewUnstartedServer()
+		s.Handler = func(c smpptest.Conn, p pdu.Body) {
+			if p.Header().ID != pdu.SubmitSMID {
+				return
+			}
+			if i == 0 && atomic.LoadInt32(&down) == 1 {
+				c.Close()
+				return
+			}
+			atomic.AddInt32(&count[i], 1)
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		}
+		s.Start()
+		defer s.Close()
+		servers[i] = s
+		clients = append(clients, &Transmitter{
+			Addr:         s.Addr(),
+			User:         smpptest.DefaultUser,
+			Passwd:       smpptest.DefaultPasswd,
+			RespTimeout:  100 * time.Millisecond,
+			BindInterval: time.Hour,
+		})
+	}
+	clients[1] = &Transceiver{
+		Addr:   servers[1].Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	p := NewPool(clients...)
+	events := make(chan *PoolConnStatus, 10)
+	p.ConnStatusHandler = func(st ConnStatus) { events <- st.(*PoolConnStatus) }
+	defer p.Close()
+	if _, err := p.Submit(&ShortMessage{}); err != ErrNotConnected {
+		t.Fatalf("unexpected error before bind: want %v, have %v", ErrNotConnected, err)
+	}
+	status := p.Bind()
+	for i := 0; i < 2; i++ {
+		st := (<-status).(*PoolConnStatus)
+		if st.Status() != Connected {
+			t.Fatalf("client %d: %v", st.Index, st.Error())
+		}
+	}
+	if n := p.Len(); n != 2 {
+		t.Fatalf("unexpected number of clients: want 2, have %d", n)
+	}
+	sm := &ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")}
+	for i := 0; i < 10; i++ {
+		if _, err := p.Submit(sm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a, b := atomic.LoadInt32(&count[0]), atomic.LoadInt32(&count[1]); a != 5 || b != 5 {
+		t.Fatalf("unexpected distribution: %d and %d", a, b)
+	}
+	// The client that loses its connection leaves the pool.
+	atomic.StoreInt32(&down, 1)
+	for i := 0; i < 2; i++ {
+		p.Submit(sm)
+	}
+	st := (<-status).(*PoolConnStatus)
+	if st.Index != 0 || st.Status() == Connected {
+		t.Fatalf("unexpected status of client %d: %s", st.Index, st.Status())
+	}
+	// The handler gets every change, in order.
+	var have []ConnStatusID
+	for len(have) < 3 {
+		select {
+		case st := <-events:
+			if st.Index == 0 {
+				have = append(have, st.Status())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for status changes: %v", have)
+		}
+	}
+	if want := []ConnStatusID{Connected, Disconnected, Reconnecting}; fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Fatalf("unexpected status changes: want %v, have %v", want, have)
+	}
+	if n := p.Len(); n != 1 {
+		t.Fatalf("unexpected number of clients: want 1, have %d", n)
+	}
+	before := atomic.LoadInt32(&count[1])
+	for i := 0; i < 4; i++ {
+		if _, err := p.Submit(sm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b := atomic.LoadInt32(&count[1]) - before; b != 4 {
+		t.Fatalf("unexpected number of messages: want 4, have %d", b)
+	}
+}
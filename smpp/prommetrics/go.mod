@@ -0,0 +1,10 @@
+module github.com/fiorix/go-smpp/v2/smpp/prommetrics
+
+go 1.15
+
+require (
+	github.com/fiorix/go-smpp/v2 v2.0.0
+	github.com/prometheus/client_golang v1.12.2
+)
+
+replace github.com/fiorix/go-smpp/v2 => ../..
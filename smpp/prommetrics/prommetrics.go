@@ -0,0 +1,128 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package prommetrics implements smpp.Metrics with Prometheus
+// collectors. It is a module of its own, so that importing the smpp
+// package does not pull in the Prometheus client.
+package prommetrics
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fiorix/go-smpp/v2/smpp"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+)
+
+// Metrics implements smpp.Metrics and prometheus.Collector. Register
+// it with a prometheus.Registerer to export the measurements.
+type Metrics struct {
+	sent         *prometheus.CounterVec
+	received     *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	timeouts     *prometheus.CounterVec
+	inflight     prometheus.Gauge
+	queued       prometheus.Gauge
+	status       *prometheus.CounterVec
+	reconnects   prometheus.Counter
+	bindFailures prometheus.Counter
+
+	connected int32
+}
+
+// New returns a Metrics with collectors named after the given
+// namespace, e.g. "smpp", and labeled with the constant labels, if any,
+// e.g. to tell apart the clients of a Pool.
+func New(namespace string, labels prometheus.Labels) *Metrics {
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{Namespace: namespace, Name: name, Help: help, ConstLabels: labels}
+	}
+	return &Metrics{
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts(
+			opts("pdus_sent_total", "PDUs written to the connection.")), []string{"pdu"}),
+		received: prometheus.NewCounterVec(prometheus.CounterOpts(
+			opts("pdus_received_total", "PDUs read from the connection.")), []string{"pdu"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        "response_latency_seconds",
+			Help:        "Round-trip time of requests.",
+			ConstLabels: labels,
+			Buckets:     prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"pdu"}),
+		timeouts: prometheus.NewCounterVec(prometheus.CounterOpts(
+			opts("timeouts_total", "Requests without a response within the timeout.")), []string{"pdu"}),
+		inflight: prometheus.NewGauge(prometheus.GaugeOpts(
+			opts("window_inflight", "Requests waiting for a response."))),
+		queued: prometheus.NewGauge(prometheus.GaugeOpts(
+			opts("window_queued", "Callers waiting for a free slot in the window."))),
+		status: prometheus.NewCounterVec(prometheus.CounterOpts(
+			opts("conn_status_total", "Connection status changes.")), []string{"status"}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts(
+			opts("reconnects_total", "Connections after the first one."))),
+		bindFailures: prometheus.NewCounter(prometheus.CounterOpts(
+			opts("bind_failures_total", "Failed bind attempts."))),
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.sent, m.received, m.latency, m.timeouts, m.inflight,
+		m.queued, m.status, m.reconnects, m.bindFailures,
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements the prometheus.Collector interface.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// PDUSent implements the smpp.Metrics interface.
+func (m *Metrics) PDUSent(id pdu.ID) {
+	m.sent.WithLabelValues(id.String()).Inc()
+}
+
+// PDUReceived implements the smpp.Metrics interface.
+func (m *Metrics) PDUReceived(id pdu.ID) {
+	m.received.WithLabelValues(id.String()).Inc()
+}
+
+// Latency implements the smpp.Metrics interface.
+func (m *Metrics) Latency(id pdu.ID, d time.Duration) {
+	m.latency.WithLabelValues(id.String()).Observe(d.Seconds())
+}
+
+// Timeout implements the smpp.Metrics interface.
+func (m *Metrics) Timeout(id pdu.ID) {
+	m.timeouts.WithLabelValues(id.String()).Inc()
+}
+
+// Window implements the smpp.Metrics interface.
+func (m *Metrics) Window(s smpp.WindowStatus) {
+	m.inflight.Set(float64(s.Inflight))
+	m.queued.Set(float64(s.Queued))
+}
+
+// ConnStatus implements the smpp.Metrics interface.
+func (m *Metrics) ConnStatus(s smpp.ConnStatusID) {
+	m.status.WithLabelValues(s.String()).Inc()
+	switch s {
+	case smpp.Connected:
+		if !atomic.CompareAndSwapInt32(&m.connected, 0, 1) {
+			m.reconnects.Inc()
+		}
+	case smpp.BindFailed:
+		m.bindFailures.Inc()
+	}
+}
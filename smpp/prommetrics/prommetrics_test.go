@@ -0,0 +1,59 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package prommetrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/fiorix/go-smpp/v2/smpp"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New("smpp", prometheus.Labels{"bind": "a"})
+	var _ smpp.Metrics = m
+	r := prometheus.NewPedanticRegistry()
+	if err := r.Register(m); err != nil {
+		t.Fatal(err)
+	}
+	m.PDUSent(pdu.SubmitSMID)
+	m.PDUSent(pdu.SubmitSMID)
+	m.PDUReceived(pdu.SubmitSMRespID)
+	m.Latency(pdu.SubmitSMID, 20*time.Millisecond)
+	m.Timeout(pdu.SubmitSMID)
+	m.Window(smpp.WindowStatus{Size: 10, Inflight: 3})
+	m.ConnStatus(smpp.Connected)
+	m.ConnStatus(smpp.Disconnected)
+	m.ConnStatus(smpp.BindFailed)
+	m.ConnStatus(smpp.Connected)
+	want := `
+# HELP smpp_pdus_sent_total PDUs written to the connection.
+# TYPE smpp_pdus_sent_total counter
+smpp_pdus_sent_total{bind="a",pdu="SubmitSM"} 2
+# HELP smpp_reconnects_total Connections after the first one.
+# TYPE smpp_reconnects_total counter
+smpp_reconnects_total{bind="a"} 1
+# HELP smpp_bind_failures_total Failed bind attempts.
+# TYPE smpp_bind_failures_total counter
+smpp_bind_failures_total{bind="a"} 1
+# HELP smpp_window_inflight Requests waiting for a response.
+# TYPE smpp_window_inflight gauge
+smpp_window_inflight{bind="a"} 3
+`
+	err := testutil.GatherAndCompare(r, strings.NewReader(want),
+		"smpp_pdus_sent_total", "smpp_reconnects_total",
+		"smpp_bind_failures_total", "smpp_window_inflight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(m, "smpp_response_latency_seconds"); n != 1 {
+		t.Fatalf("unexpected number of latency histograms: want 1, have %d", n)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+)
+
+// ErrNotReceipt is returned by ParseDeliveryReceipt when the PDU is
+// not a delivery receipt.
+var ErrNotReceipt = errors.New("not a delivery receipt")
+
+// Message type bits of esm_class in deliver_sm and data_sm.
+const (
+	esmClassTypeMask     = 0x3c
+	esmClassReceipt      = 0x04 // SMSC delivery receipt.
+	esmClassIntermediate = 0x20 // Intermediate delivery notification.
+)
+
+// DeliveryReceipt contains the data of a delivery receipt, sent by the
+// SMSC in a deliver_sm or data_sm PDU when a message submitted with
+// registered_delivery reaches a final (or intermediate) state.
+//
+// Fields that are missing from the receipt are left empty.
+type DeliveryReceipt struct {
+	ID           string    // Message ID, from receipted_message_id if present.
+	Src          string    // Address of the recipient of the original message.
+	Dst          string    // Address of the sender of the original message.
+	Sub          int       // Number of messages originally submitted.
+	Dlvrd        int       // Number of messages delivered.
+	SubmitDate   time.Time // Time the original message was submitted, in UTC.
+	DoneDate     time.Time // Time the message reached its state, in UTC.
+	Stat         string    // Status as sent in the text, e.g. DELIVRD.
+	State        string    // Message state, e.g. DELIVERED.
+	Err          string    // Network specific error code.
+	Text         string    // First characters of the original message.
+	Intermediate bool      // Intermediate delivery notification.
+}
+
+// ReceiptHandlerFunc is the handler function that a Receiver or
+// Transceiver calls when a delivery receipt arrives.
+type ReceiptHandlerFunc func(r *DeliveryReceipt)
+
+// MatchID reports whether the receipt is for the message with the
+// given ID, as returned by Submit. Since some SMSCs return the message
+// ID in hexadecimal in the submit response and in decimal in the
+// receipt, or vice versa, IDs are compared by MessageIDKey.
+func (r *DeliveryReceipt) MatchID(msgid string) bool {
+	return MessageIDKey(r.ID) == MessageIDKey(msgid)
+}
+
+// MessageIDKey returns the key of a message ID under which matching
+// IDs are equal, for indexing messages waiting for their receipts.
+// IDs with hex letters (a-f) are taken as hexadecimal, other numeric
+// IDs as decimal, so that "ff" matches "255" but "10" does not match
+// "16". Other IDs match regardless of case.
+func MessageIDKey(id string) string {
+	base := 10
+	if strings.IndexAny(id, "abcdefABCDEF") >= 0 {
+		base = 16
+	}
+	n, err := strconv.ParseUint(id, base, 64)
+	if err != nil {
+		return strings.ToLower(id)
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// receiptStates maps the stat values of receipts to message states.
+var receiptStates = map[string]string{
+	"ENROUTE": "ENROUTE",
+	"DELIVRD": "DELIVERED",
+	"EXPIRED": "EXPIRED",
+	"DELETED": "DELETED",
+	"UNDELIV": "UNDELIVERABLE",
+	"ACCEPTD": "ACCEPTED",
+	"UNKNOWN": "UNKNOWN",
+	"REJECTD": "REJECTED",
+}
+
+// receiptKey matches the keys of the receipt text.
+var receiptKey = regexp.MustCompile(`(?i)(?:^|\s)(id|sub|dlvrd|submit[ _]date|done[ _]date|stat|err|text)\s*:`)
+
+// receiptDate layouts, with and without seconds.
+var receiptDate = []string{"0601021504", "060102150405"}
+
+// ParseDeliveryReceipt parses the delivery receipt in a deliver_sm or
+// data_sm PDU. The PDU is a receipt if its esm_class says so, if it
+// carries the receipted_message_id TLV, or if its text looks like a
+// receipt, since some SMSCs do not set esm_class.
+func ParseDeliveryReceipt(p pdu.Body) (*DeliveryReceipt, error) {
+	switch p.Header().ID {
+	case pdu.DeliverSMID, pdu.DataSMID:
+	default:
+		return nil, ErrNotReceipt
+	}
+	f := p.Fields()
+	tlv := p.TLVFields()
+	text := fieldString(f, pdufield.ShortMessage)
+	if v := tlv[pdutlv.TagMessagePayload]; text == "" && v != nil {
+		text = string(v.Bytes())
+	}
+	esm := fieldUint8(f, pdufield.ESMClass) & esmClassTypeMask
+	switch {
+	case esm == esmClassReceipt, esm == esmClassIntermediate:
+	case tlv[pdutlv.TagReceiptedMessageID] != nil:
+	case esm == 0 && isReceiptText(text):
+	default:
+		return nil, ErrNotReceipt
+	}
+	r := &DeliveryReceipt{
+		Src:          fieldString(f, pdufield.SourceAddr),
+		Dst:          fieldString(f, pdufield.DestinationAddr),
+		Intermediate: esm == esmClassIntermediate,
+	}
+	parseReceiptText(r, text)
+	if id, ok := p.TLVFields().CString(pdutlv.TagReceiptedMessageID); ok && id != "" {
+		r.ID = id
+	}
+	if state, ok := p.TLVFields().Uint8(pdutlv.TagMessageState); ok {
+		r.State = messageState(state)
+	} else {
+		r.State = receiptStates[strings.ToUpper(r.Stat)]
+	}
+	return r, nil
+}
+
+// isReceiptText reports whether text looks like a delivery receipt.
+func isReceiptText(text string) bool {
+	s := strings.ToLower(text)
+	return strings.HasPrefix(s, "id:") && strings.Contains(s, "stat:")
+}
+
+// parseReceiptText sets the fields of r from the receipt text, e.g.
+// "id:1 sub:001 dlvrd:001 submit date:1502011200 done date:1502011201
+// stat:DELIVRD err:000 text:Hello". Keys are case insensitive and may
+// come in any order, except for text which is always the last one.
+func parseReceiptText(r *DeliveryReceipt, text string) {
+	m := receiptKey.FindAllStringSubmatchIndex(text, -1)
+	for i, idx := range m {
+		key := strings.ToLower(text[idx[2]:idx[3]])
+		end := len(text)
+		if i+1 < len(m) && key != "text" {
+			end = m[i+1][0]
+		}
+		v := text[idx[1]:end]
+		if key != "text" {
+			v = strings.TrimSpace(v)
+		}
+		switch strings.Replace(key, "_", " ", 1) {
+		case "id":
+			r.ID = v
+		case "sub":
+			r.Sub, _ = strconv.Atoi(v)
+		case "dlvrd":
+			r.Dlvrd, _ = strconv.Atoi(v)
+		case "submit date":
+			r.SubmitDate = parseReceiptDate(v)
+		case "done date":
+			r.DoneDate = parseReceiptDate(v)
+		case "stat":
+			r.Stat = v
+		case "err":
+			r.Err = v
+		case "text":
+			r.Text = v
+			return
+		}
+	}
+}
+
+// parseReceiptDate parses the YYMMDDhhmm[ss] dates of receipts, and
+// returns the zero time if v is not valid.
+func parseReceiptDate(v string) time.Time {
+	for _, layout := range receiptDate {
+		if len(v) != len(layout) {
+			continue
+		}
+		if t, err := time.ParseInLocation(layout, v, time.UTC); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseReceipt returns the delivery receipt in p if there is a handler
+// for it, or nil.
+func parseReceipt(rf ReceiptHandlerFunc, p pdu.Body) *DeliveryReceipt {
+	if rf == nil {
+		return nil
+	}
+	r, err := ParseDeliveryReceipt(p)
+	if err != nil {
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,127 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+)
+
+func TestParseDeliveryReceipt(t *testing.T) {
+	test := []struct {
+		esm  uint8
+		text string
+		tlv  pdutlv.Fields
+		want DeliveryReceipt
+	}{
+		{
+			esm:  0x04,
+			text: "id:1234567890 sub:001 dlvrd:001 submit date:1502011200 done date:150201120005 stat:DELIVRD err:000 text:Hello id:x",
+			want: DeliveryReceipt{
+				ID:         "1234567890",
+				Sub:        1,
+				Dlvrd:      1,
+				SubmitDate: time.Date(2015, 2, 1, 12, 0, 0, 0, time.UTC),
+				DoneDate:   time.Date(2015, 2, 1, 12, 0, 5, 0, time.UTC),
+				Stat:       "DELIVRD",
+				State:      "DELIVERED",
+				Err:        "000",
+				Text:       "Hello id:x",
+			},
+		},
+		{
+			esm:  0x20,
+			text: "ID:ab12 Stat:ENROUTE",
+			want: DeliveryReceipt{
+				ID:           "ab12",
+				Stat:         "ENROUTE",
+				State:        "ENROUTE",
+				Intermediate: true,
+			},
+		},
+		{
+			// No esm_class, state and id from TLVs.
+			text: "stat:UNDELIV err:001",
+			tlv: pdutlv.Fields{
+				pdutlv.TagReceiptedMessageID: pdutlv.CString("ff"),
+				pdutlv.TagMessageStateOption: uint8(3),
+			},
+			want: DeliveryReceipt{
+				ID:    "ff",
+				Stat:  "UNDELIV",
+				State: "EXPIRED",
+				Err:   "001",
+			},
+		},
+		{
+			// No esm_class, only text.
+			text: "id:42 submit_date:bogus stat:REJECTD",
+			want: DeliveryReceipt{
+				ID:    "42",
+				Stat:  "REJECTD",
+				State: "REJECTED",
+			},
+		},
+	}
+	for _, tc := range test {
+		p := pdu.NewDeliverSM()
+		f := p.Fields()
+		f.Set(pdufield.SourceAddr, "5511999999999")
+		f.Set(pdufield.DestinationAddr, "root")
+		f.Set(pdufield.ESMClass, tc.esm)
+		f.Set(pdufield.ShortMessage, tc.text)
+		for k, v := range tc.tlv {
+			p.TLVFields().Set(k, v)
+		}
+		r, err := ParseDeliveryReceipt(p)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.text, err)
+		}
+		tc.want.Src = "5511999999999"
+		tc.want.Dst = "root"
+		if *r != tc.want {
+			t.Fatalf("%q: unexpected receipt:\nwant %#v\nhave %#v", tc.text, tc.want, *r)
+		}
+	}
+}
+
+func TestParseDeliveryReceiptNotReceipt(t *testing.T) {
+	p := pdu.NewDeliverSM()
+	p.Fields().Set(pdufield.ShortMessage, "id:1 is not a receipt")
+	if _, err := ParseDeliveryReceipt(p); err != ErrNotReceipt {
+		t.Fatalf("unexpected error: want %v, have %v", ErrNotReceipt, err)
+	}
+	if _, err := ParseDeliveryReceipt(pdu.NewSubmitSM(nil)); err != ErrNotReceipt {
+		t.Fatalf("unexpected error: want %v, have %v", ErrNotReceipt, err)
+	}
+}
+
+func TestDeliveryReceiptMatchID(t *testing.T) {
+	test := []struct {
+		id, msgid string
+		want      bool
+	}{
+		{"abc", "ABC", true},
+		{"255", "ff", true},
+		{"FF", "0255", true},
+		{"255", "254", false},
+		{"xyz", "255", false},
+		{"16", "10", false},
+		{"100", "64", false},
+		{"10", "16", false},
+		{"0042", "42", true},
+		{"1a", "26", true},
+	}
+	for _, tc := range test {
+		r := &DeliveryReceipt{ID: tc.id}
+		if have := r.MatchID(tc.msgid); have != tc.want {
+			t.Fatalf("MatchID(%q) on %q: want %t, have %t", tc.msgid, tc.id, tc.want, have)
+		}
+	}
+}
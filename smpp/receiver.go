@@ -6,13 +6,17 @@ package smpp
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
 )
 
 // Receiver implements an SMPP client receiver.
@@ -22,20 +26,28 @@ type Receiver struct {
 	Passwd               string
 	SystemType           string
 	EnquireLink          time.Duration
-	EnquireLinkTimeout   time.Duration // Time after last EnquireLink response when connection considered down
-	BindInterval         time.Duration // Binding retry interval
-	MergeInterval        time.Duration // Time in which Receiver waits for the parts of the long messages
-	MergeCleanupInterval time.Duration // How often to cleanup expired message parts
+	EnquireLinkTimeout   time.Duration           // Time after last EnquireLink response when connection considered down
+	BindInterval         time.Duration           // Binding retry interval
+	MergeInterval        time.Duration           // Time in which Receiver waits for the parts of the long messages
+	MergeCleanupInterval time.Duration           // How often to cleanup expired message parts
+	MergeExpiredHandler  MergeExpiredHandlerFunc // Called with long messages that expired incomplete, optional.
 	TLS                  *tls.Config
 	Handler              HandlerFunc
+	AlertHandler         AlertHandlerFunc   // Called on alert_notification, optional.
+	ReceiptHandler       ReceiptHandlerFunc // Called on delivery receipts instead of Handler, optional.
 	SkipAutoRespondIDs   []pdu.ID
+	InterfaceVersion     uint8                 // Interface version requested on bind, default V34.
+	Metrics              Metrics               // Receives measurements, optional.
+	WrapConn             func(Conn) Conn       // Wraps new connections, e.g. with PDULogger, optional.
+	ConnStatusHandler    ConnStatusHandlerFunc // Called on every connection status change, optional.
 
 	chanClose chan struct{}
+	version   uint32 // negotiated interface version, atomic.
 
 	// struct which holds the map of MergeHolders for the merging of the long incoming messages.
-	// It is used only if the incoming PDU holds UDH data and Receiver has MergeInterval > 0.
+	// It is used only if the incoming PDU holds UDH data or SAR TLVs and Receiver has MergeInterval > 0.
 	mg struct {
-		mergeHolders map[int]*MergeHolder
+		mergeHolders map[mergeKey]*MergeHolder
 		sync.Mutex
 	}
 
@@ -49,12 +61,28 @@ type Receiver struct {
 // when a new PDU arrives.
 type HandlerFunc func(p pdu.Body)
 
+// MergeExpiredHandlerFunc is the handler function that a Receiver
+// calls with the parts of a long message that did not arrive complete
+// within MergeInterval.
+type MergeExpiredHandlerFunc func(mh *MergeHolder)
+
 // MergeHolder is a struct which holds the slice of MessageParts for the merging of a long incoming message.
 type MergeHolder struct {
-	MessageID     int
-	MessageParts  []*MessagePart // Slice with the parts of the message
+	MessageID     int            // Reference number of the message
+	Src           string         // Source address of the message
+	Dst           string         // Destination address of the message
+	MessageParts  []*MessagePart // Slice with the parts of the message, ordered by PartID
 	PartsCount    int
 	LastWriteTime time.Time
+
+	merged bool // All parts arrived, kept to detect duplicates
+}
+
+// mergeKey identifies a long message. Reference numbers are only
+// unique per originator and recipient.
+type mergeKey struct {
+	src, dst string
+	ref      int
 }
 
 // MessagePart is a struct which holds the data of the part of a long incoming message.
@@ -69,6 +97,12 @@ type MessagePart struct {
 //
 // Bind implements the ClientConn interface.
 func (r *Receiver) Bind() <-chan ConnStatus {
+	return r.bind(nil)
+}
+
+// bind starts the Receiver using the given function to establish
+// new connections, or Dial when dial is nil.
+func (r *Receiver) bind(dial func() (Conn, error)) <-chan ConnStatus {
 	r.cl.Lock()
 	defer r.cl.Unlock()
 
@@ -84,24 +118,29 @@ func (r *Receiver) Bind() <-chan ConnStatus {
 		EnquireLink:        r.EnquireLink,
 		EnquireLinkTimeout: r.EnquireLinkTimeout,
 		Status:             make(chan ConnStatus, 1),
+		StatusHandler:      r.ConnStatusHandler,
 		BindFunc:           r.bindFunc,
+		DialFunc:           dial,
 		BindInterval:       r.BindInterval,
+		Metrics:            r.Metrics,
+		WrapConn:           r.WrapConn,
 	}
 	r.cl.client = c
 
-	c.init()
-	go c.Bind()
-
-	// Set up message merging if requested
+	// Set up message merging if requested, before bindFunc can
+	// reset it.
 	if r.MergeInterval > 0 {
 		if r.MergeCleanupInterval == 0 {
 			r.MergeCleanupInterval = 1 * time.Second
 		}
 
-		r.mg.mergeHolders = make(map[int]*MergeHolder)
+		r.mg.mergeHolders = make(map[mergeKey]*MergeHolder)
 		go r.mergeCleaner()
 	}
 
+	c.init()
+	go c.Bind()
+
 	return c.Status
 }
 
@@ -111,7 +150,7 @@ func (r *Receiver) bindFunc(c Conn) error {
 	f.Set(pdufield.SystemID, r.User)
 	f.Set(pdufield.Password, r.Passwd)
 	f.Set(pdufield.SystemType, r.SystemType)
-	resp, err := bind(c, p)
+	resp, err := bind(c, p, r.InterfaceVersion)
 	if err != nil {
 		return err
 	}
@@ -119,22 +158,29 @@ func (r *Receiver) bindFunc(c Conn) error {
 		return fmt.Errorf("unexpected response for BindReceiver: %s",
 			resp.Header().ID)
 	}
+	atomic.StoreUint32(&r.version, uint32(negotiate(r.InterfaceVersion, resp)))
 
 	// Clean the map in case of rebind, because message id numbering resets after reconnection
 	// and older IDs are no longer valid
 	if r.MergeInterval > 0 {
 		r.mg.Lock()
-		r.mg.mergeHolders = make(map[int]*MergeHolder)
+		r.mg.mergeHolders = make(map[mergeKey]*MergeHolder)
 		r.mg.Unlock()
 	}
 
-	if r.Handler != nil {
+	if r.Handler != nil || r.AlertHandler != nil || r.ReceiptHandler != nil {
 		go r.handlePDU()
 	}
 
 	return nil
 }
 
+// NegotiatedVersion returns the interface version negotiated with the
+// SMSC on the last bind, or 0 if not bound yet.
+func (r *Receiver) NegotiatedVersion() uint8 {
+	return uint8(atomic.LoadUint32(&r.version))
+}
+
 func idInList(id pdu.ID, list []pdu.ID) bool {
 	for _, x := range list {
 		if x == id {
@@ -145,25 +191,18 @@ func idInList(id pdu.ID, list []pdu.ID) bool {
 }
 
 func (r *Receiver) handlePDU() {
-	var (
-		ok                bool
-		sm                *pdufield.SM
-		udhList           *pdufield.UDHList
-		msgID, partsCount int
-		mh                *MergeHolder
-		orderedBodies     []*bytes.Buffer
-	)
 	autoRespondDeliver := !idInList(pdu.DeliverSMID, r.SkipAutoRespondIDs)
+	autoRespondData := !idInList(pdu.DataSMID, r.SkipAutoRespondIDs)
 
-loop:
 	for {
 		p, err := r.cl.Read()
 		if err != nil || p == nil {
 			break
 		}
 
-		if p == nil {
-			break
+		if p.Header().ID == pdu.AlertNotificationID && r.AlertHandler != nil {
+			r.AlertHandler(newAlert(p))
+			continue
 		}
 
 		if p.Header().ID == pdu.DeliverSMID && autoRespondDeliver { // Send DeliverSMResp
@@ -171,100 +210,248 @@ loop:
 			r.cl.Write(pResp)
 		}
 
-		if r.MergeInterval == 0 { // Handle the PDU if merging is not needed
-			r.Handler(p)
+		if p.Header().ID == pdu.DataSMID && autoRespondData { // Send DataSMResp
+			pResp := pdu.NewDataSMRespSeq(p.Header().Seq)
+			r.cl.Write(pResp)
+		}
+
+		if dr := parseReceipt(r.ReceiptHandler, p); dr != nil {
+			r.ReceiptHandler(dr)
 			continue
 		}
 
-		sm, ok = p.Fields()[pdufield.ShortMessage].(*pdufield.SM)
-		if !ok {
-			// PDU is malformed, do not process
+		if r.Handler == nil {
 			continue
 		}
 
-		udhList, ok = p.Fields()[pdufield.GSMUserData].(*pdufield.UDHList)
-		if !ok { // Check if GSMUserData is present inside the PDU, do not try to merge if it's not
+		if r.MergeInterval == 0 { // Handle the PDU if merging is not needed
 			r.Handler(p)
 			continue
 		}
 
-		for _, udh := range udhList.Data {
-			switch udh.IEI.Data {
-			case 0x00: // Concatenated short messages, 8-bit reference number
-				if int(udh.IELength.Data) != 3 { // Contains message ID, parts count and part number
-					// PDU is malformed, do not process
-					break
-				}
+		if p = r.merge(p); p != nil {
+			r.Handler(p)
+		}
+	}
+}
 
-				// Get message ID and total count of its parts
-				msgID = int(udh.IEData.Data[0])
-				partsCount = int(udh.IEData.Data[1])
+// merge adds p to the parts of the long message it belongs to. It
+// returns the PDU with the merged message once all parts arrived, p
+// itself if it is not part of a long message, or nil.
+func (r *Receiver) merge(p pdu.Body) pdu.Body {
+	udh, data := userData(p)
+	ref, total, seq, ok := concatInfo(p, udh)
+	if !ok || total == 1 {
+		return p
+	}
+	f := p.Fields()
+	k := mergeKey{
+		src: fieldString(f, pdufield.SourceAddr),
+		dst: fieldString(f, pdufield.DestinationAddr),
+		ref: ref,
+	}
 
-				// Check if message part was already added to a MergeHolder
-				r.mg.Lock()
-				if mh, ok = r.mg.mergeHolders[msgID]; !ok {
-					mh = &MergeHolder{
-						MessageID:  msgID,
-						PartsCount: partsCount,
-					}
+	r.mg.Lock()
+	defer r.mg.Unlock()
+	mh, ok := r.mg.mergeHolders[k]
+	if ok && mh.merged {
+		if mh.hasPart(seq, data) { // Duplicate part, e.g. resent after a lost response
+			mh.LastWriteTime = time.Now()
+			return nil
+		}
+		ok = false // New message reusing the reference number
+	}
+	if !ok {
+		mh = &MergeHolder{
+			MessageID:  ref,
+			Src:        k.src,
+			Dst:        k.dst,
+			PartsCount: total,
+		}
+		r.mg.mergeHolders[k] = mh
+	}
+	mh.LastWriteTime = time.Now()
 
-					r.mg.mergeHolders[msgID] = mh
-				}
-				r.mg.Unlock()
-
-				// Add current part of the message to the slice
-				mh.MessageParts = append(mh.MessageParts, &MessagePart{
-					PartID: int(udh.IEData.Data[2]),
-					Data:   bytes.NewBuffer(sm.Data),
-				})
-				mh.LastWriteTime = time.Now()
-
-				// Check if we have all the parts of the message
-				if len(mh.MessageParts) != mh.PartsCount {
-					continue loop
-				}
+	if seq > mh.PartsCount {
+		// PDU is malformed, do not process
+		return nil
+	}
+	for _, mp := range mh.MessageParts {
+		if mp.PartID == seq { // Duplicate part
+			return nil
+		}
+	}
+	mh.MessageParts = append(mh.MessageParts, &MessagePart{
+		PartID: seq,
+		Data:   bytes.NewBuffer(data),
+	})
+
+	// Check if we have all the parts of the message
+	if len(mh.MessageParts) != mh.PartsCount {
+		return nil
+	}
+	mh.merged = true
 
-				// Order up PDUs
-				orderedBodies = make([]*bytes.Buffer, partsCount)
-				for _, mp := range mh.MessageParts {
-					orderedBodies[mp.PartID-1] = mp.Data
-				}
+	// Merge PDUs
+	mh.sortParts()
+	var buf bytes.Buffer
+	for _, mp := range mh.MessageParts {
+		buf.Write(mp.Data.Bytes())
+	}
+	setUserData(p, otherIEs(udh), buf.Bytes())
+	return p
+}
 
-				// Merge PDUs
-				var buf bytes.Buffer
-				for _, body := range orderedBodies {
-					buf.Write(body.Bytes())
-				}
+// hasPart reports whether the message has the given part.
+func (mh *MergeHolder) hasPart(id int, data []byte) bool {
+	for _, mp := range mh.MessageParts {
+		if mp.PartID == id {
+			return bytes.Equal(mp.Data.Bytes(), data)
+		}
+	}
+	return false
+}
 
-				p.Fields().Set(pdufield.ShortMessage, buf.Bytes())
+// sortParts orders the parts of the message by PartID.
+func (mh *MergeHolder) sortParts() {
+	sort.Slice(mh.MessageParts, func(i, j int) bool {
+		return mh.MessageParts[i].PartID < mh.MessageParts[j].PartID
+	})
+}
 
-				// Handle
-				r.Handler(p)
-			}
+// esmClassUDHI is the esm_class flag for messages with a user data header.
+const esmClassUDHI = 0x40
+
+// userData returns the user data header and the message of p, from
+// either the short message or the message_payload TLV. The header is
+// only present if esm_class has the UDHI flag set.
+func userData(p pdu.Body) (udh *pdufield.UDHList, data []byte) {
+	if sm, ok := p.Fields()[pdufield.ShortMessage].(*pdufield.SM); ok && len(sm.Data) > 0 {
+		data = sm.Data
+	} else if v := p.TLVFields()[pdutlv.TagMessagePayload]; v != nil {
+		data = v.Bytes()
+	}
+	if fieldUint8(p.Fields(), pdufield.ESMClass)&esmClassUDHI == 0 {
+		return nil, data
+	}
+	udh, text, err := pdufield.ParseUDH(data)
+	if err != nil {
+		return nil, data
+	}
+	return udh, text
+}
+
+// setUserData replaces the message of p with data, where p carries
+// its message. The user data header is replaced with udh, and the UDHI
+// flag is cleared if udh is empty.
+func setUserData(p pdu.Body, udh *pdufield.UDHList, data []byte) {
+	f := p.Fields()
+	h := udh.Header()
+	if esm := fieldUint8(f, pdufield.ESMClass); esm&esmClassUDHI != 0 && len(h) == 0 {
+		f.Set(pdufield.ESMClass, esm&^esmClassUDHI)
+	}
+	data = append(h, data...)
+	if sm, ok := f[pdufield.ShortMessage].(*pdufield.SM); (!ok || len(sm.Data) == 0) &&
+		p.TLVFields()[pdutlv.TagMessagePayload] != nil {
+		p.TLVFields().Set(pdutlv.TagMessagePayload, data)
+		return
+	}
+	f.Set(pdufield.ShortMessage, data)
+}
+
+// otherIEs returns the information elements of udh other than the
+// concatenation ones, e.g. national language shift tables.
+func otherIEs(udh *pdufield.UDHList) *pdufield.UDHList {
+	other := pdufield.NewUDHList()
+	if udh == nil {
+		return other
+	}
+	for _, ie := range udh.Data {
+		if iei := ie.IEI.Data; iei != pdufield.IEIConcat8 && iei != pdufield.IEIConcat16 {
+			other.Data = append(other.Data, ie)
+		}
+	}
+	return other
+}
+
+// concatInfo returns the reference number, total number of parts and
+// sequence number of the part of a long message, from either the
+// concatenation information element of the user data header, with
+// 8-bit (IEI 0x00) or 16-bit (IEI 0x08) reference, or the SAR TLVs
+// of p.
+func concatInfo(p pdu.Body, udh *pdufield.UDHList) (ref, total, seq int, ok bool) {
+	if udh != nil {
+		if ref, total, seq, ok = udh.Concat(); ok {
+			return ref, total, seq, total > 0 && seq > 0 && seq <= total
 		}
 	}
+	refNum, ok1 := p.TLVFields().Uint16(pdutlv.TagSarMsgRefNum)
+	totalSeg, ok2 := p.TLVFields().Uint8(pdutlv.TagSarTotalSegments)
+	segSeq, ok3 := p.TLVFields().Uint8(pdutlv.TagSarSegmentSeqnum)
+	if !ok1 || !ok2 || !ok3 {
+		return 0, 0, 0, false
+	}
+	ref, total, seq = int(refNum), int(totalSeg), int(segSeq)
+	return ref, total, seq, total > 0 && seq > 0 && seq <= total
 }
 
 func (r *Receiver) mergeCleaner() {
-	timer := time.NewTimer(r.MergeCleanupInterval)
+	ticker := time.NewTicker(r.MergeCleanupInterval)
+	defer ticker.Stop()
 
 	for {
 		select {
-		case <-timer.C:
+		case <-ticker.C:
+			var expired []*MergeHolder
 			r.mg.Lock()
-			for _, mHolder := range r.mg.mergeHolders {
+			for k, mHolder := range r.mg.mergeHolders {
 				if time.Since(mHolder.LastWriteTime) > r.MergeInterval { // Message has expired, remove
-					delete(r.mg.mergeHolders, mHolder.MessageID)
+					delete(r.mg.mergeHolders, k)
+					if !mHolder.merged {
+						expired = append(expired, mHolder)
+					}
 				}
 			}
 			r.mg.Unlock()
 
+			if r.MergeExpiredHandler == nil {
+				continue
+			}
+			for _, mHolder := range expired {
+				mHolder.sortParts()
+				r.MergeExpiredHandler(mHolder)
+			}
+
 		case <-r.chanClose:
 			return
 		}
 	}
 }
 
+// State returns the last connection status of the Receiver, for
+// health checks, or 0 if Bind was not called.
+func (r *Receiver) State() ConnStatusID {
+	r.cl.Lock()
+	defer r.cl.Unlock()
+	if r.cl.client == nil {
+		return 0
+	}
+	return r.cl.State()
+}
+
+// Shutdown gracefully terminates the connection: it sends Unbind and
+// waits for UnbindResp before closing the connection, returning
+// ctx.Err() if ctx is done first.
+func (r *Receiver) Shutdown(ctx context.Context) error {
+	r.cl.Lock()
+	defer r.cl.Unlock()
+	if r.cl.client == nil {
+		return ErrNotConnected
+	}
+	r.stopMerge()
+	return r.cl.shutdown(ctx)
+}
+
 // Close implements the ClientConn interface.
 func (r *Receiver) Close() error {
 	r.cl.Lock()
@@ -272,6 +459,15 @@ func (r *Receiver) Close() error {
 	if r.cl.client == nil {
 		return ErrNotConnected
 	}
-	close(r.chanClose)
+	r.stopMerge()
 	return r.cl.Close()
 }
+
+// stopMerge stops the merge cleaner, once.
+func (r *Receiver) stopMerge() {
+	select {
+	case <-r.chanClose:
+	default:
+		close(r.chanClose)
+	}
+}
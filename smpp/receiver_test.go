@@ -5,11 +5,14 @@
 package smpp
 
 import (
+	"context"
 	"testing"
 	"time"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/smpptest"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
 )
 
 func TestReceiver(t *testing.T) {
@@ -43,4 +46,246 @@ func TestReceiver(t *testing.T) {
 	case <-time.After(time.Second):
 		t.Fatal("timeout waiting for server to echo")
 	}
-}
\ No newline at end of file
+}
+
+func TestReceiverShutdown(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	r := &Receiver{
+		Addr:    s.Addr(),
+		User:    smpptest.DefaultUser,
+		Passwd:  smpptest.DefaultPasswd,
+		Handler: func(p pdu.Body) {},
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReceiverDataSM(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	ack := make(chan pdu.Body, 1)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID == pdu.DataSMRespID {
+			ack <- p
+		}
+	}
+	s.Start()
+	defer s.Close()
+	rc := make(chan pdu.Body, 1)
+	r := &Receiver{
+		Addr:    s.Addr(),
+		User:    smpptest.DefaultUser,
+		Passwd:  smpptest.DefaultPasswd,
+		Handler: func(p pdu.Body) { rc <- p },
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	p := pdu.NewDataSM(pdutlv.Fields{pdutlv.TagMessagePayload: "*100#"})
+	s.BroadcastMessage(p)
+	select {
+	case m := <-rc:
+		if m.Header().ID != pdu.DataSMID {
+			t.Fatalf("unexpected PDU: %s", m.Header().ID)
+		}
+		if f := m.TLVFields()[pdutlv.TagMessagePayload]; f == nil || f.String() != "*100#" {
+			t.Fatalf("unexpected message_payload: %#v", f)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for data_sm")
+	}
+	select {
+	case m := <-ack:
+		if m.Header().Seq != p.Header().Seq {
+			t.Fatalf("unexpected seq: want %d, have %d",
+				p.Header().Seq, m.Header().Seq)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for data_sm_resp")
+	}
+}
+
+func TestReceiverAlertNotification(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	rc := make(chan *Alert, 1)
+	r := &Receiver{
+		Addr:         s.Addr(),
+		User:         smpptest.DefaultUser,
+		Passwd:       smpptest.DefaultPasswd,
+		Handler:      func(p pdu.Body) { t.Errorf("unexpected PDU: %s", p.Header().ID) },
+		AlertHandler: func(a *Alert) { rc <- a },
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	p := pdu.NewAlertNotification()
+	f := p.Fields()
+	f.Set(pdufield.SourceAddrTON, 1)
+	f.Set(pdufield.SourceAddr, "5511999999999")
+	f.Set(pdufield.EsmeAddr, "root")
+	p.TLVFields().Set(pdutlv.TagMsAvailabilityStatus, uint8(MSUnavailable))
+	s.BroadcastMessage(p)
+	select {
+	case a := <-rc:
+		want := Alert{
+			Src:          "5511999999999",
+			SrcTON:       1,
+			EsmeAddr:     "root",
+			Availability: MSUnavailable,
+		}
+		if *a != want {
+			t.Fatalf("unexpected alert: want %#v, have %#v", want, *a)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for alert_notification")
+	}
+}
+
+func TestReceiverDeliveryReceipt(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	rc := make(chan *DeliveryReceipt, 1)
+	r := &Receiver{
+		Addr:           s.Addr(),
+		User:           smpptest.DefaultUser,
+		Passwd:         smpptest.DefaultPasswd,
+		Handler:        func(p pdu.Body) { t.Errorf("unexpected PDU: %s", p.Header().ID) },
+		ReceiptHandler: func(r *DeliveryReceipt) { rc <- r },
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	p := pdu.NewDeliverSM()
+	f := p.Fields()
+	f.Set(pdufield.ESMClass, 0x04)
+	f.Set(pdufield.ShortMessage, "id:foobar sub:001 dlvrd:001 stat:DELIVRD err:000 text:Lorem")
+	s.BroadcastMessage(p)
+	select {
+	case dr := <-rc:
+		if dr.ID != "foobar" || dr.State != "DELIVERED" || dr.Text != "Lorem" {
+			t.Fatalf("unexpected receipt: %#v", dr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for delivery receipt")
+	}
+}
+
+func TestReceiverMerge(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	rc := make(chan pdu.Body, 10)
+	ec := make(chan *MergeHolder, 1)
+	r := &Receiver{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+		Handler: func(p pdu.Body) {
+			if p.Header().ID == pdu.DeliverSMID {
+				rc <- p
+			}
+		},
+		MergeInterval:        500 * time.Millisecond,
+		MergeCleanupInterval: 100 * time.Millisecond,
+		MergeExpiredHandler:  func(mh *MergeHolder) { ec <- mh },
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	udh := func(src string, ie []byte, text string) pdu.Body {
+		p := pdu.NewDeliverSM()
+		f := p.Fields()
+		f.Set(pdufield.SourceAddr, src)
+		f.Set(pdufield.DestinationAddr, "root")
+		f.Set(pdufield.ESMClass, 0x40)
+		f.Set(pdufield.ShortMessage, append(append([]byte{byte(len(ie))}, ie...), text...))
+		return p
+	}
+	sar := func(src string, ref, total, seq uint8, text string) pdu.Body {
+		p := pdu.NewDeliverSM()
+		f := p.Fields()
+		f.Set(pdufield.SourceAddr, src)
+		f.Set(pdufield.DestinationAddr, "root")
+		f.Set(pdufield.ShortMessage, text)
+		tlv := p.TLVFields()
+		tlv.Set(pdutlv.TagSarMsgRefNum, []byte{0, ref})
+		tlv.Set(pdutlv.TagSarTotalSegments, total)
+		tlv.Set(pdutlv.TagSarSegmentSeqnum, seq)
+		return p
+	}
+	for _, p := range []pdu.Body{
+		// 16-bit reference, out of order and with a duplicate.
+		udh("a", []byte{0x08, 0x04, 0x12, 0x34, 0x02, 0x02}, " world"),
+		udh("a", []byte{0x08, 0x04, 0x12, 0x34, 0x02, 0x01}, "hello"),
+		udh("a", []byte{0x08, 0x04, 0x12, 0x34, 0x02, 0x01}, "hello"),
+		// 8-bit reference, same reference from different sources.
+		udh("b", []byte{0x00, 0x03, 0x05, 0x02, 0x01}, "foo"),
+		udh("c", []byte{0x00, 0x03, 0x05, 0x02, 0x01}, "lorem"),
+		udh("b", []byte{0x00, 0x03, 0x05, 0x02, 0x02}, "bar"),
+		udh("c", []byte{0x00, 0x03, 0x05, 0x02, 0x02}, " ipsum"),
+		// SAR TLVs.
+		sar("d", 7, 2, 1, "sar"),
+		sar("d", 7, 2, 2, " tlv"),
+		// Other information elements are kept.
+		udh("f", []byte{0x00, 0x03, 0x01, 0x02, 0x02, 0x25, 0x01, 0x01}, "b"),
+		udh("f", []byte{0x00, 0x03, 0x01, 0x02, 0x01, 0x25, 0x01, 0x01}, "a"),
+		// Incomplete message.
+		sar("e", 1, 3, 2, "lost"),
+	} {
+		s.BroadcastMessage(p)
+	}
+	want := []string{"a:hello world", "b:foobar", "c:lorem ipsum", "d:sar tlv", "f:\x03\x25\x01\x01ab"}
+	for _, w := range want {
+		select {
+		case p := <-rc:
+			f := p.Fields()
+			have := f[pdufield.SourceAddr].String() + ":" + f[pdufield.ShortMessage].String()
+			if have != w {
+				t.Fatalf("unexpected message: want %q, have %q", w, have)
+			}
+			if esm := f[pdufield.ESMClass].Bytes()[0]; (esm&0x40 != 0) != (w[0] == 'f') {
+				t.Fatalf("unexpected esm_class: %#x", esm)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %q", w)
+		}
+	}
+	select {
+	case mh := <-ec:
+		if mh.Src != "e" || mh.MessageID != 1 || mh.PartsCount != 3 ||
+			len(mh.MessageParts) != 1 || mh.MessageParts[0].Data.String() != "lost" {
+			t.Fatalf("unexpected expired message: %#v", mh)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for expired message")
+	}
+	select {
+	case p := <-rc:
+		t.Fatalf("unexpected message: %#v", p.Fields())
+	default:
+	}
+}
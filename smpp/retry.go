@@ -0,0 +1,94 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+)
+
+// RetryPolicy decides whether a request of a Transmitter or
+// Transceiver that failed is sent again.
+//
+// Requests are retried with a new sequence number, over the same or,
+// after a reconnection, a new connection, so that a late response to
+// an earlier attempt is not taken for the response of the retry.
+// Delivery is at-most-once unless the policy retries ErrTimeout: a
+// request that timed out may have been accepted by the SMSC, and
+// sending it again may deliver the message twice.
+//
+// With a RetryPolicy, requests throttled by the SMSC are retried by the
+// policy only, after the back-off of the FlowControl, if any.
+type RetryPolicy interface {
+	// Retry is called with the number of attempts made so far and
+	// the error of the last one, a pdu.Status if the SMSC rejected
+	// the request. It returns the delay before sending the request
+	// again, and whether to send it at all.
+	Retry(attempts int, err error) (time.Duration, bool)
+}
+
+// BackoffRetry is a RetryPolicy that retries transient failures with
+// exponential back-off and jitter. Each delay is picked at random
+// between half and all of Min doubled for every attempt, up to Max.
+type BackoffRetry struct {
+	MaxAttempts   int                  // Attempts including the first one, default 3.
+	Min           time.Duration        // Delay after the first attempt, default 1s.
+	Max           time.Duration        // Max delay, default 30s.
+	Transient     func(err error) bool // Classifies errors, IsTransient if nil.
+	RetryTimeouts bool                 // Retry ErrTimeout, see RetryPolicy.
+}
+
+// Retry implements the RetryPolicy interface.
+func (b *BackoffRetry) Retry(attempts int, err error) (time.Duration, bool) {
+	max := b.MaxAttempts
+	if max == 0 {
+		max = 3
+	}
+	transient := b.Transient
+	if transient == nil {
+		transient = IsTransient
+	}
+	switch {
+	case attempts >= max:
+		return 0, false
+	case err == ErrTimeout:
+		if !b.RetryTimeouts {
+			return 0, false
+		}
+	case !transient(err):
+		return 0, false
+	}
+	d, dmax := b.Min, b.Max
+	if d == 0 {
+		d = time.Second
+	}
+	if dmax == 0 {
+		dmax = 30 * time.Second
+	}
+	for i := 1; i < attempts && d < dmax; i++ {
+		d *= 2
+	}
+	if d > dmax {
+		d = dmax
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
+
+// IsTransient reports whether err is a transient failure of a request:
+// a temporary pdu.Status, ErrNotConnected while the client reconnects,
+// or ErrMaxWindowSize. ErrTimeout is not, see RetryPolicy.
+func IsTransient(err error) bool {
+	var s pdu.Status
+	switch {
+	case errors.As(err, &s):
+		return s.Temporary()
+	case errors.Is(err, ErrNotConnected), errors.Is(err, ErrMaxWindowSize):
+		return true
+	}
+	return false
+}
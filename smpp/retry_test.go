@@ -0,0 +1,210 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+)
+
+func TestIsTransient(t *testing.T) {
+	test := []struct {
+		err  error
+		want bool
+	}{
+		{pdu.StatusSysErr, true},
+		{pdu.StatusThrottled, true},
+		{pdu.Status(0x0b), false}, // invalid destination address
+		{fmt.Errorf("submit: %w", pdu.StatusMsgQFull), true},
+		{ErrNotConnected, true},
+		{ErrMaxWindowSize, true},
+		{ErrTimeout, false},
+		{ErrNotBound, false},
+		{errors.New("foobar"), false},
+	}
+	for _, tc := range test {
+		if have := IsTransient(tc.err); have != tc.want {
+			t.Fatalf("%v: want %t, have %t", tc.err, tc.want, have)
+		}
+	}
+}
+
+func TestBackoffRetry(t *testing.T) {
+	b := &BackoffRetry{Min: 100 * time.Millisecond, Max: 300 * time.Millisecond}
+	test := []struct {
+		attempts int
+		err      error
+		ok       bool
+		min, max time.Duration
+	}{
+		{1, pdu.StatusSysErr, true, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, ErrNotConnected, true, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, pdu.StatusSysErr, false, 0, 0},
+		{1, ErrTimeout, false, 0, 0},
+		{1, pdu.Status(0x0b), false, 0, 0},
+	}
+	for _, tc := range test {
+		d, ok := b.Retry(tc.attempts, tc.err)
+		if ok != tc.ok || d < tc.min || d > tc.max {
+			t.Fatalf("attempt %d, %v: unexpected retry: %t after %s", tc.attempts, tc.err, ok, d)
+		}
+	}
+	b.MaxAttempts = 5
+	b.RetryTimeouts = true
+	if d, ok := b.Retry(4, ErrTimeout); !ok || d < 150*time.Millisecond || d > 300*time.Millisecond {
+		t.Fatalf("unexpected retry of timeout: %t after %s", ok, d)
+	}
+	b.Transient = func(err error) bool { return err == pdu.Status(0x0b) }
+	if _, ok := b.Retry(1, pdu.Status(0x0b)); !ok {
+		t.Fatal("custom transient error not retried")
+	}
+}
+
+func TestSubmitRetry(t *testing.T) {
+	var n int32
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		switch atomic.AddInt32(&n, 1) {
+		case 1:
+			r.Header().Status = pdu.StatusSysErr
+		case 2:
+			return // times out
+		default:
+			r.Fields().Set(pdufield.MessageID, "foobar")
+		}
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		RespTimeout: 50 * time.Millisecond,
+		RetryPolicy: &BackoffRetry{Min: time.Millisecond},
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")}
+	// System error is retried, timeout is not.
+	if _, err := tx.Submit(sm); err != ErrTimeout {
+		t.Fatalf("unexpected error: want %v, have %v", ErrTimeout, err)
+	}
+	if sm, err := tx.Submit(sm); err != nil || sm.RespID() != "foobar" {
+		t.Fatalf("unexpected response: %v", err)
+	}
+	if n := atomic.LoadInt32(&n); n != 3 {
+		t.Fatalf("unexpected number of attempts: want 3, have %d", n)
+	}
+}
+
+func TestSubmitRetryNewSeq(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		seqs  []uint32
+		stale pdu.Body
+	)
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		seqs = append(seqs, p.Header().Seq)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		if len(seqs) == 1 {
+			// Let the first attempt time out, and answer it late
+			// with an error that must not be taken for the retry's.
+			r.Header().Status = pdu.Status(0x0b)
+			stale = r
+			return
+		}
+		if stale != nil {
+			c.Write(stale)
+			stale = nil
+		}
+		r.Fields().Set(pdufield.MessageID, "foobar")
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		RespTimeout: 50 * time.Millisecond,
+		RetryPolicy: &BackoffRetry{Min: time.Millisecond, RetryTimeouts: true},
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")}
+	if sm, err := tx.Submit(sm); err != nil || sm.RespID() != "foobar" {
+		t.Fatalf("unexpected response: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seqs) != 2 || seqs[0] == seqs[1] {
+		t.Fatalf("unexpected sequence numbers of the attempts: %v", seqs)
+	}
+}
+
+func TestSubmitRetryThrottled(t *testing.T) {
+	var n int32
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		atomic.AddInt32(&n, 1)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		r.Header().Status = pdu.StatusThrottled
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		FlowControl: &FlowControl{Backoff: time.Millisecond},
+		RetryPolicy: &BackoffRetry{MaxAttempts: 2, Min: time.Millisecond},
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")}
+	if _, err := tx.Submit(sm); err != pdu.StatusThrottled {
+		t.Fatalf("unexpected error: want %v, have %v", pdu.StatusThrottled, err)
+	}
+	// The retries of the flow control do not stack on the policy's.
+	if n := atomic.LoadInt32(&n); n != 2 {
+		t.Fatalf("unexpected number of attempts: want 2, have %d", n)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package server provides an SMPP server (SMSC) with pluggable
+// authentication and per-session state.
+//
+// The Server answers bind, enquire_link and unbind on its own, rejects
+// operations that are not allowed in the current bind state, replies
+// with generic_nack to PDUs it cannot decode, and calls the configured
+// handlers for submit_sm, submit_multi, data_sm, query_sm, cancel_sm
+// and replace_sm.
+package server
@@ -0,0 +1,358 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+)
+
+// ErrServerClosed is returned by Serve and ListenAndServe after
+// Close is called.
+var ErrServerClosed = errors.New("server closed")
+
+// respMask is set in the command_id of all response PDUs.
+const respMask pdu.ID = 0x80000000
+
+// Authenticator authenticates bind requests.
+type Authenticator interface {
+	// Authenticate is called for every bind request. Returning a
+	// pdu.Status sets the command_status of the bind response, and
+	// any other error is reported as pdu.StatusBindFail.
+	Authenticate(s *Session, systemID, passwd, systemType string) error
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary
+// functions as Authenticator.
+type AuthenticatorFunc func(s *Session, systemID, passwd, systemType string) error
+
+// Authenticate implements the Authenticator interface.
+func (f AuthenticatorFunc) Authenticate(s *Session, systemID, passwd, systemType string) error {
+	return f(s, systemID, passwd, systemType)
+}
+
+// StaticAuth is an Authenticator backed by a map of system_id to
+// password.
+type StaticAuth map[string]string
+
+// Authenticate implements the Authenticator interface.
+func (a StaticAuth) Authenticate(s *Session, systemID, passwd, systemType string) error {
+	want, ok := a[systemID]
+	if !ok {
+		return pdu.StatusInvSysID
+	}
+	if want != passwd {
+		return pdu.StatusInvPaswd
+	}
+	return nil
+}
+
+// HandlerFunc handles a request PDU from a bound session. The resp PDU
+// is the response with the sequence number already set, and handlers
+// are supposed to fill in its fields (e.g. message_id). Returning a
+// pdu.Status sets the command_status of resp, and any other error is
+// reported as pdu.StatusSysErr.
+type HandlerFunc func(s *Session, req, resp pdu.Body) error
+
+// Server is an SMPP server (SMSC).
+//
+// Sessions that fail authentication are closed after the bind
+// response is sent. Operations for which no handler is configured
+// are answered with pdu.StatusInvCmdID.
+type Server struct {
+	Addr        string        // Address to listen on, default :2775.
+	TLS         *tls.Config   // TLS server settings, optional.
+	SystemID    string        // system_id in bind responses.
+	Auth        Authenticator // Binds are rejected if nil.
+	IdleTimeout time.Duration // Close sessions with no traffic, optional.
+
+	SubmitSM    HandlerFunc
+	SubmitMulti HandlerFunc
+	DataSM      HandlerFunc
+	QuerySM     HandlerFunc
+	CancelSM    HandlerFunc
+	ReplaceSM   HandlerFunc
+
+	// RespHandler is called with responses to PDUs written with
+	// Session.Write, e.g. deliver_sm_resp or generic_nack. Optional.
+	RespHandler func(s *Session, p pdu.Body)
+
+	// SessionClosed is called when a session terminates. Optional.
+	SessionClosed func(s *Session)
+
+	ErrorLog *log.Logger // Uses the log package if nil.
+
+	mu       sync.Mutex
+	l        net.Listener
+	sessions map[*Session]struct{}
+	closed   bool
+}
+
+// ListenAndServe listens on Addr and then calls Serve.
+func (srv *Server) ListenAndServe() error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":2775"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// Serve accepts connections on the given listener and starts a new
+// session for each one. It blocks until Close is called, and always
+// returns a non-nil error.
+func (srv *Server) Serve(l net.Listener) error {
+	if srv.TLS != nil {
+		l = tls.NewListener(l, srv.TLS)
+	}
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	srv.l = l
+	if srv.sessions == nil {
+		srv.sessions = make(map[*Session]struct{})
+	}
+	srv.mu.Unlock()
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			srv.mu.Lock()
+			closed := srv.closed
+			srv.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s := newSession(c)
+		srv.mu.Lock()
+		srv.sessions[s] = struct{}{}
+		srv.mu.Unlock()
+		go srv.serve(s)
+	}
+}
+
+// Sessions returns the currently open sessions.
+func (srv *Server) Sessions() []*Session {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	ss := make([]*Session, 0, len(srv.sessions))
+	for s := range srv.sessions {
+		ss = append(ss, s)
+	}
+	return ss
+}
+
+// Close stops the server and terminates all sessions.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.closed = true
+	var err error
+	if srv.l != nil {
+		err = srv.l.Close()
+	}
+	for s := range srv.sessions {
+		s.Close()
+	}
+	return err
+}
+
+func (srv *Server) logf(format string, v ...interface{}) {
+	if srv.ErrorLog != nil {
+		srv.ErrorLog.Printf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
+}
+
+// serve handles the PDUs of a session until it terminates.
+func (srv *Server) serve(s *Session) {
+	defer func() {
+		s.Close()
+		srv.mu.Lock()
+		delete(srv.sessions, s)
+		srv.mu.Unlock()
+		if srv.SessionClosed != nil {
+			srv.SessionClosed(s)
+		}
+	}()
+	for {
+		p, hdr, err := s.read(srv.IdleTimeout)
+		if err != nil && hdr == nil {
+			if err != io.EOF && s.State() != Closed {
+				srv.logf("smpp/server: read from %s failed: %v", s.RemoteAddr(), err)
+			}
+			return
+		}
+		if err != nil {
+			var status pdu.Status
+			switch {
+			case err == errCmdLen:
+				status = pdu.StatusInvCmdLen
+			case hdr.ID.String() == "":
+				status = pdu.StatusInvCmdID
+			default: // malformed body
+				status = pdu.StatusSysErr
+			}
+			nack := pdu.NewGenericNACK()
+			nack.Header().Seq = hdr.Seq
+			nack.Header().Status = status
+			if werr := s.write(nack); werr != nil || err == errCmdLen {
+				return
+			}
+			continue
+		}
+		if !srv.handle(s, p) {
+			return
+		}
+	}
+}
+
+// handle processes a single PDU, and returns false if the session
+// should be terminated.
+func (srv *Server) handle(s *Session, p pdu.Body) bool {
+	id := p.Header().ID
+	if id&respMask != 0 {
+		if srv.RespHandler != nil {
+			srv.RespHandler(s, p)
+		}
+		return true
+	}
+	state := s.State()
+	switch id {
+	case pdu.BindTransmitterID, pdu.BindReceiverID, pdu.BindTransceiverID:
+		return srv.bind(s, p) == nil
+	case pdu.EnquireLinkID:
+		return srv.reply(s, pdu.NewEnquireLinkRespSeq(p.Header().Seq), nil)
+	case pdu.UnbindID:
+		if state == Open {
+			break
+		}
+		resp := pdu.NewUnbindResp()
+		resp.Header().Seq = p.Header().Seq
+		s.write(resp)
+		return false
+	}
+	resp, h, allowed := srv.route(id, state)
+	if resp == nil {
+		nack := pdu.NewGenericNACK()
+		nack.Header().Seq = p.Header().Seq
+		nack.Header().Status = pdu.StatusInvCmdID
+		return srv.reply(s, nack, nil)
+	}
+	resp.Header().Seq = p.Header().Seq
+	switch {
+	case !allowed:
+		resp.Header().Status = pdu.StatusInvBndSts
+	case h == nil:
+		resp.Header().Status = pdu.StatusInvCmdID
+	default:
+		return srv.reply(s, resp, h(s, p, resp))
+	}
+	return srv.reply(s, resp, nil)
+}
+
+// route returns the response PDU and handler for the given request,
+// and whether the request is allowed in the given state. The response
+// is nil for unsupported requests.
+func (srv *Server) route(id pdu.ID, state State) (pdu.Body, HandlerFunc, bool) {
+	tx := state == BoundTX || state == BoundTRX
+	switch id {
+	case pdu.SubmitSMID:
+		return pdu.NewSubmitSMResp(), srv.SubmitSM, tx
+	case pdu.SubmitMultiID:
+		return pdu.NewSubmitMultiResp(), srv.SubmitMulti, tx
+	case pdu.DataSMID:
+		return pdu.NewDataSMResp(), srv.DataSM, tx
+	case pdu.QuerySMID:
+		return pdu.NewQuerySMResp(), srv.QuerySM, tx
+	case pdu.CancelSMID:
+		return pdu.NewCancelSMResp(), srv.CancelSM, tx
+	case pdu.ReplaceSMID:
+		return pdu.NewReplaceSMResp(), srv.ReplaceSM, tx
+	case pdu.UnbindID:
+		return pdu.NewUnbindResp(), nil, false
+	}
+	return nil, nil, false
+}
+
+// reply sets the status of resp based on err and writes it to the
+// session. It returns false if the write fails.
+func (srv *Server) reply(s *Session, resp pdu.Body, err error) bool {
+	if err != nil {
+		status, ok := err.(pdu.Status)
+		if !ok {
+			status = pdu.StatusSysErr
+		}
+		resp.Header().Status = status
+	}
+	return s.write(resp) == nil
+}
+
+// bind authenticates the session and updates its state. It returns
+// an error if the session should be terminated.
+func (srv *Server) bind(s *Session, p pdu.Body) error {
+	var (
+		resp  pdu.Body
+		state State
+	)
+	switch p.Header().ID {
+	case pdu.BindTransmitterID:
+		resp, state = pdu.NewBindTransmitterResp(), BoundTX
+	case pdu.BindReceiverID:
+		resp, state = pdu.NewBindReceiverResp(), BoundRX
+	default:
+		resp, state = pdu.NewBindTransceiverResp(), BoundTRX
+	}
+	resp.Header().Seq = p.Header().Seq
+	resp.Fields().Set(pdufield.SystemID, srv.SystemID)
+	if s.State() != Open {
+		resp.Header().Status = pdu.StatusAlyBnd
+		return s.write(resp)
+	}
+	f := p.Fields()
+	var systemID, passwd, systemType string
+	if v := f[pdufield.SystemID]; v != nil {
+		systemID = v.String()
+	}
+	if v := f[pdufield.Password]; v != nil {
+		passwd = v.String()
+	}
+	if v := f[pdufield.SystemType]; v != nil {
+		systemType = v.String()
+	}
+	var err error
+	if srv.Auth == nil {
+		err = pdu.StatusBindFail
+	} else {
+		err = srv.Auth.Authenticate(s, systemID, passwd, systemType)
+	}
+	if err != nil {
+		status, ok := err.(pdu.Status)
+		if !ok {
+			status = pdu.StatusBindFail
+		}
+		resp.Header().Status = status
+		s.write(resp)
+		return err
+	}
+	s.setState(state, systemID, systemType)
+	return s.write(resp)
+}
@@ -0,0 +1,247 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+)
+
+func newTestServer(t *testing.T, srv *Server) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.Auth == nil {
+		srv.Auth = StaticAuth{"client": "secret"}
+	}
+	go srv.Serve(l)
+	return l.Addr().String()
+}
+
+// dial connects to addr and returns a session for the client side.
+func dial(t *testing.T, addr string) *Session {
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newSession(c)
+}
+
+func readPDU(t *testing.T, s *Session) pdu.Body {
+	p, _, err := s.read(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestServerSubmitSM(t *testing.T) {
+	srv := &Server{
+		SystemID: "smsc",
+		SubmitSM: func(s *Session, req, resp pdu.Body) error {
+			if s.SystemID() != "client" {
+				t.Errorf("unexpected system_id: %q", s.SystemID())
+			}
+			if s.State() != BoundTX {
+				t.Errorf("unexpected state: %s", s.State())
+			}
+			resp.Fields().Set(pdufield.MessageID, "foobar")
+			return nil
+		},
+	}
+	defer srv.Close()
+	tx := &smpp.Transmitter{
+		Addr:   newTestServer(t, srv),
+		User:   "client",
+		Passwd: "secret",
+	}
+	defer tx.Close()
+	if conn := <-tx.Bind(); conn.Status() != smpp.Connected {
+		t.Fatal(conn.Error())
+	}
+	sm, err := tx.Submit(&smpp.ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgid := sm.RespID(); msgid != "foobar" {
+		t.Fatalf("unexpected msgid: want foobar, have %q", msgid)
+	}
+	// query_sm has no handler configured.
+	_, err = tx.QuerySM("root", "foobar", 0, 0)
+	if err != pdu.StatusInvCmdID {
+		t.Fatalf("unexpected error: want %v, have %v", pdu.StatusInvCmdID, err)
+	}
+}
+
+func TestServerBindFailed(t *testing.T) {
+	srv := &Server{}
+	defer srv.Close()
+	tx := &smpp.Transmitter{
+		Addr:   newTestServer(t, srv),
+		User:   "client",
+		Passwd: "wrong",
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != smpp.BindFailed || conn.Error() != pdu.StatusInvPaswd {
+		t.Fatalf("unexpected status: %s (%v)", conn.Status(), conn.Error())
+	}
+}
+
+func TestServerSessionState(t *testing.T) {
+	srv := &Server{}
+	defer srv.Close()
+	c := dial(t, newTestServer(t, srv))
+	defer c.Close()
+	// submit_sm before bind.
+	p := pdu.NewSubmitSM(nil)
+	if err := c.write(p); err != nil {
+		t.Fatal(err)
+	}
+	r := readPDU(t, c)
+	if r.Header().ID != pdu.SubmitSMRespID || r.Header().Status != pdu.StatusInvBndSts {
+		t.Fatalf("unexpected response: %#v", r.Header())
+	}
+	// bind as receiver.
+	p = pdu.NewBindReceiver()
+	p.Fields().Set(pdufield.SystemID, "client")
+	p.Fields().Set(pdufield.Password, "secret")
+	if err := c.write(p); err != nil {
+		t.Fatal(err)
+	}
+	if r = readPDU(t, c); r.Header().Status != 0 {
+		t.Fatalf("unexpected bind status: %v", r.Header().Status)
+	}
+	// bind again.
+	if err := c.write(p); err != nil {
+		t.Fatal(err)
+	}
+	if r = readPDU(t, c); r.Header().Status != pdu.StatusAlyBnd {
+		t.Fatalf("unexpected bind status: %v", r.Header().Status)
+	}
+	// submit_sm is not allowed for receivers.
+	p = pdu.NewSubmitSM(nil)
+	if err := c.write(p); err != nil {
+		t.Fatal(err)
+	}
+	if r = readPDU(t, c); r.Header().Status != pdu.StatusInvBndSts {
+		t.Fatalf("unexpected submit_sm status: %v", r.Header().Status)
+	}
+	// enquire_link.
+	p = pdu.NewEnquireLink()
+	if err := c.write(p); err != nil {
+		t.Fatal(err)
+	}
+	r = readPDU(t, c)
+	if r.Header().ID != pdu.EnquireLinkRespID || r.Header().Seq != p.Header().Seq {
+		t.Fatalf("unexpected response: %#v", r.Header())
+	}
+	// the server can deliver to receivers.
+	ss := srv.Sessions()
+	if len(ss) != 1 {
+		t.Fatalf("unexpected number of sessions: %d", len(ss))
+	}
+	if err := ss[0].Write(pdu.NewDeliverSM()); err != nil {
+		t.Fatal(err)
+	}
+	if r = readPDU(t, c); r.Header().ID != pdu.DeliverSMID {
+		t.Fatalf("unexpected PDU: %s", r.Header().ID)
+	}
+	// unbind.
+	p = pdu.NewUnbind()
+	if err := c.write(p); err != nil {
+		t.Fatal(err)
+	}
+	if r = readPDU(t, c); r.Header().ID != pdu.UnbindRespID {
+		t.Fatalf("unexpected PDU: %s", r.Header().ID)
+	}
+}
+
+func TestServerGenericNACK(t *testing.T) {
+	srv := &Server{}
+	defer srv.Close()
+	c := dial(t, newTestServer(t, srv))
+	defer c.Close()
+	b := make([]byte, pdu.HeaderLen)
+	binary.BigEndian.PutUint32(b[0:4], pdu.HeaderLen)
+	binary.BigEndian.PutUint32(b[4:8], 0x00000666)
+	binary.BigEndian.PutUint32(b[12:16], 42)
+	if _, err := c.rwc.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	r := readPDU(t, c)
+	if r.Header().ID != pdu.GenericNACKID {
+		t.Fatalf("unexpected PDU: %s", r.Header().ID)
+	}
+	want := pdu.Header{Len: pdu.HeaderLen, ID: pdu.GenericNACKID, Status: pdu.StatusInvCmdID, Seq: 42}
+	if *r.Header() != want {
+		t.Fatalf("unexpected header: want %#v, have %#v", want, *r.Header())
+	}
+}
+
+func TestServerInvalidCommandLength(t *testing.T) {
+	srv := &Server{}
+	defer srv.Close()
+	c := dial(t, newTestServer(t, srv))
+	defer c.Close()
+	b := make([]byte, pdu.HeaderLen)
+	binary.BigEndian.PutUint32(b[0:4], 4)
+	binary.BigEndian.PutUint32(b[4:8], uint32(pdu.SubmitSMID))
+	binary.BigEndian.PutUint32(b[12:16], 42)
+	if _, err := c.rwc.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	r := readPDU(t, c)
+	want := pdu.Header{Len: pdu.HeaderLen, ID: pdu.GenericNACKID, Status: pdu.StatusInvCmdLen, Seq: 42}
+	if *r.Header() != want {
+		t.Fatalf("unexpected header: want %#v, have %#v", want, *r.Header())
+	}
+	if _, _, err := c.read(time.Second); err != io.EOF {
+		t.Fatalf("session not closed: %v", err)
+	}
+}
+
+func TestServerMalformedPDU(t *testing.T) {
+	srv := &Server{}
+	defer srv.Close()
+	c := dial(t, newTestServer(t, srv))
+	defer c.Close()
+	// enquire_link with a TLV longer than the PDU.
+	b := make([]byte, pdu.HeaderLen+4)
+	binary.BigEndian.PutUint32(b[0:4], pdu.HeaderLen+4)
+	binary.BigEndian.PutUint32(b[4:8], uint32(pdu.EnquireLinkID))
+	binary.BigEndian.PutUint32(b[12:16], 42)
+	binary.BigEndian.PutUint16(b[16:18], 0x0010)
+	binary.BigEndian.PutUint16(b[18:20], 5)
+	if _, err := c.rwc.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	r := readPDU(t, c)
+	want := pdu.Header{Len: pdu.HeaderLen, ID: pdu.GenericNACKID, Status: pdu.StatusSysErr, Seq: 42}
+	if *r.Header() != want {
+		t.Fatalf("unexpected header: want %#v, have %#v", want, *r.Header())
+	}
+	// The session is still usable.
+	p := pdu.NewEnquireLink()
+	if err := c.write(p); err != nil {
+		t.Fatal(err)
+	}
+	if r = readPDU(t, c); r.Header().ID != pdu.EnquireLinkRespID {
+		t.Fatalf("unexpected PDU: %s", r.Header().ID)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+)
+
+// ErrInvalidState is returned by Session.Write when the session is not
+// bound in a mode that allows the SMSC to send the given PDU.
+var ErrInvalidState = errors.New("invalid session state")
+
+// State is the SMPP session state.
+type State uint8
+
+// Session states, as defined in SMPP 3.4 section 2.2.
+const (
+	Open State = iota
+	BoundTX
+	BoundRX
+	BoundTRX
+	Closed
+)
+
+var stateText = map[State]string{
+	Open:     "Open",
+	BoundTX:  "Bound TX",
+	BoundRX:  "Bound RX",
+	BoundTRX: "Bound TRX",
+	Closed:   "Closed",
+}
+
+// String implements the Stringer interface.
+func (s State) String() string {
+	return stateText[s]
+}
+
+// Session is a client connection to the Server.
+type Session struct {
+	rwc net.Conn
+	r   *bufio.Reader
+
+	wmu sync.Mutex
+	w   *bufio.Writer
+
+	mu         sync.Mutex
+	state      State
+	systemID   string
+	systemType string
+}
+
+func newSession(c net.Conn) *Session {
+	return &Session{
+		rwc: c,
+		r:   bufio.NewReader(c),
+		w:   bufio.NewWriter(c),
+	}
+}
+
+// State returns the current state of the session.
+func (s *Session) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// SystemID returns the system_id the client bound with, or empty
+// if the session is not bound.
+func (s *Session) SystemID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.systemID
+}
+
+// SystemType returns the system_type the client bound with.
+func (s *Session) SystemType() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.systemType
+}
+
+// RemoteAddr returns the peer address.
+func (s *Session) RemoteAddr() net.Addr {
+	return s.rwc.RemoteAddr()
+}
+
+// CanReceive returns true if the session is bound as a receiver
+// or transceiver, and can be sent deliver_sm or data_sm PDUs.
+func (s *Session) CanReceive() bool {
+	st := s.State()
+	return st == BoundRX || st == BoundTRX
+}
+
+// Write serializes the given PDU and writes it to the client.
+//
+// Request PDUs (e.g. DeliverSM) can only be written to sessions that
+// are bound as receiver or transceiver, otherwise ErrInvalidState is
+// returned. Responses to those requests are passed to the Server's
+// RespHandler.
+func (s *Session) Write(p pdu.Body) error {
+	if p.Header().ID&respMask == 0 && !s.CanReceive() {
+		return ErrInvalidState
+	}
+	return s.write(p)
+}
+
+func (s *Session) write(p pdu.Body) error {
+	var b bytes.Buffer
+	if err := p.SerializeTo(&b); err != nil {
+		return err
+	}
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	if _, err := io.Copy(s.w, &b); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// errCmdLen is returned by read along with the raw header of a PDU
+// whose command_length is invalid. The stream cannot be resynchronized
+// after it.
+var errCmdLen = errors.New("invalid command length")
+
+// read reads the next PDU off the wire. If the PDU cannot be decoded
+// but its header is valid, the header is returned along with the
+// decoding error so that a generic_nack can be sent. If the length in
+// the header is invalid, the raw header is returned with errCmdLen.
+func (s *Session) read(timeout time.Duration) (pdu.Body, *pdu.Header, error) {
+	if timeout > 0 {
+		s.rwc.SetReadDeadline(time.Now().Add(timeout))
+	}
+	b, err := s.r.Peek(pdu.HeaderLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	hdr, err := pdu.DecodeHeader(bytes.NewReader(b))
+	if err != nil {
+		return nil, &pdu.Header{
+			Len: binary.BigEndian.Uint32(b[0:4]),
+			ID:  pdu.ID(binary.BigEndian.Uint32(b[4:8])),
+			Seq: binary.BigEndian.Uint32(b[12:16]),
+		}, errCmdLen
+	}
+	b = make([]byte, hdr.Len)
+	if _, err = io.ReadFull(s.r, b); err != nil {
+		return nil, nil, err
+	}
+	p, err := pdu.Decode(bytes.NewReader(b))
+	return p, hdr, err
+}
+
+// setState updates the session state and bind information.
+func (s *Session) setState(st State, systemID, systemType string) {
+	s.mu.Lock()
+	s.state = st
+	s.systemID = systemID
+	s.systemType = systemType
+	s.mu.Unlock()
+}
+
+// Close terminates the session.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	s.state = Closed
+	s.mu.Unlock()
+	return s.rwc.Close()
+}
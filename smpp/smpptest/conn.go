@@ -9,8 +9,9 @@ import (
 	"bytes"
 	"io"
 	"net"
+	"sync"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
 )
 
 // Conn implements a server side connection.
@@ -29,6 +30,8 @@ type Conn interface {
 type conn struct {
 	rwc net.Conn
 	r   *bufio.Reader
+
+	wmu sync.Mutex // serializes writes from handlers and broadcasts.
 	w   *bufio.Writer
 }
 
@@ -57,6 +60,8 @@ func (c *conn) Write(p pdu.Body) error {
 	if err != nil {
 		return err
 	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
 	_, err = io.Copy(c.w, &b)
 	if err != nil {
 		return err
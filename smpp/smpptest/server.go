@@ -13,8 +13,9 @@ import (
 	"net"
 	"sync"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
 )
 
 // Default settings.
@@ -37,6 +38,11 @@ type Server struct {
 	TLS     *tls.Config
 	Handler HandlerFunc
 
+	// InterfaceVersion is the highest interface version of the
+	// server, sent in the sc_interface_version TLV of bind responses
+	// when not 0.
+	InterfaceVersion uint8
+
 	conns []Conn
 	mu    sync.Mutex
 	l     net.Listener
@@ -96,6 +102,7 @@ func (srv *Server) Close() {
 
 // Serve accepts new clients and handle them by authenticating the
 // first PDU, expected to be a Bind PDU, then echoing all other PDUs.
+// Unbind is answered with UnbindResp, and closes the connection.
 func (srv *Server) Serve() {
 	for {
 		cli, err := srv.l.Accept()
@@ -104,13 +111,17 @@ func (srv *Server) Serve() {
 		}
 
 		c := newConn(cli)
+		srv.mu.Lock()
 		srv.conns = append(srv.conns, c)
+		srv.mu.Unlock()
 		go srv.handle(c)
 	}
 }
 
 // BroadcastMessage broadcasts a test PDU to the all bound clients
 func (srv *Server) BroadcastMessage(p pdu.Body) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
 	for i := range srv.conns {
 		srv.conns[i].Write(p)
 	}
@@ -133,6 +144,10 @@ func (srv *Server) handle(c *conn) {
 			}
 			break
 		}
+		if p.Header().ID == pdu.UnbindID {
+			c.Write(pdu.NewUnbindRespSeq(p.Header().Seq))
+			return
+		}
 		srv.Handler(c, p)
 	}
 }
@@ -167,12 +182,16 @@ func (srv *Server) auth(c *conn) error {
 		return errors.New("invalid passwd")
 	}
 	resp.Fields().Set(pdufield.SystemID, DefaultSystemID)
+	if srv.InterfaceVersion != 0 {
+		resp.TLVFields().Set(pdutlv.TagScInterfaceVersion, srv.InterfaceVersion)
+	}
 
 	return c.Write(resp)
 }
 
 // EchoHandler is the default Server HandlerFunc, and echoes back
-// any PDUs received.
+// any PDUs received, except cancel_sm and replace_sm, which are
+// answered with their successful responses.
 func EchoHandler(cli Conn, m pdu.Body) {
 	// log.Printf("smpptest: echo PDU from %s: %#v", cli.RemoteAddr(), m)
 	//
@@ -184,5 +203,12 @@ func EchoHandler(cli Conn, m pdu.Body) {
 	//     cli.Write(resp)
 	//
 	// We just echo m back:
-	cli.Write(m)
+	switch m.Header().ID {
+	case pdu.CancelSMID:
+		cli.Write(pdu.NewCancelSMRespSeq(m.Header().Seq))
+	case pdu.ReplaceSMID:
+		cli.Write(pdu.NewReplaceSMRespSeq(m.Header().Seq))
+	default:
+		cli.Write(m)
+	}
 }
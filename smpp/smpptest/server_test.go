@@ -9,10 +9,10 @@ import (
 	"net"
 	"testing"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
 )
 
 func TestServer(t *testing.T) {
@@ -75,7 +75,7 @@ func TestServer(t *testing.T) {
 		}
 	}
 	// submit_sm + tlv field
-	p = pdu.NewSubmitSM(pdutlv.Fields{ pdutlv.TagReceiptedMessageID: pdutlv.CString("xyz123") })
+	p = pdu.NewSubmitSM(pdutlv.Fields{pdutlv.TagReceiptedMessageID: pdutlv.CString("xyz123")})
 	f = p.Fields()
 	f.Set(pdufield.SourceAddr, "foobar")
 	f.Set(pdufield.DestinationAddr, "bozo")
@@ -104,3 +104,49 @@ func TestServer(t *testing.T) {
 		}
 	}
 }
+
+func TestServerCancelReplaceSM(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c, err := net.Dial("tcp", s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	rw := newConn(c)
+	p := pdu.NewBindTransmitter()
+	f := p.Fields()
+	f.Set(pdufield.SystemID, "client")
+	f.Set(pdufield.Password, "secret")
+	if err = rw.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rw.Read(); err != nil {
+		t.Fatal(err)
+	}
+	cancel := pdu.NewCancelSM()
+	cancel.Fields().Set(pdufield.MessageID, "13")
+	cancel.Fields().Set(pdufield.SourceAddr, "foobar")
+	replace := pdu.NewReplaceSM()
+	replace.Fields().Set(pdufield.MessageID, "13")
+	replace.Fields().Set(pdufield.ShortMessage, pdutext.Raw("Lorem ipsum").Encode())
+	for _, tc := range []struct {
+		p    pdu.Body
+		want pdu.ID
+	}{
+		{cancel, pdu.CancelSMRespID},
+		{replace, pdu.ReplaceSMRespID},
+	} {
+		if err = rw.Write(tc.p); err != nil {
+			t.Fatal(err)
+		}
+		r, err := rw.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := r.Header()
+		if h.ID != tc.want || h.Seq != tc.p.Header().Seq || h.Status != 0 {
+			t.Fatalf("unexpected response to %s: %#v", tc.p.Header().ID, h)
+		}
+	}
+}
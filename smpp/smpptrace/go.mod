@@ -0,0 +1,12 @@
+module github.com/fiorix/go-smpp/v2/smpp/smpptrace
+
+go 1.15
+
+require (
+	github.com/fiorix/go-smpp/v2 v2.0.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+)
+
+replace github.com/fiorix/go-smpp/v2 => ../..
@@ -0,0 +1,279 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package smpptrace traces SMPP requests with OpenTelemetry.
+//
+// The Tracer is connection middleware for the WrapConn of Transmitter,
+// Transceiver and Receiver. Every request sent, e.g. submit_sm, gets a
+// span that is a child of the span in the context of the request, as
+// passed to SubmitContext, and ends when its response arrives. The
+// delivery receipt of a submitted message gets a span that is a child
+// of the span of its submit_sm, even when it arrives on another bind.
+//
+// The package is a separate module, which keeps OpenTelemetry out of
+// the dependencies of the smpp package.
+package smpptrace
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fiorix/go-smpp/v2/smpp"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+)
+
+// Attributes of the spans.
+const (
+	AttrSeq          = attribute.Key("smpp.seq")
+	AttrStatus       = attribute.Key("smpp.status")
+	AttrMessageID    = attribute.Key("smpp.message_id")
+	AttrReceiptState = attribute.Key("smpp.receipt.state")
+)
+
+// errSeqReused ends the span of a request whose sequence number is
+// used by a new request before its response arrives.
+var errSeqReused = errors.New("sequence number reused")
+
+// Tracer is connection middleware that traces requests and delivery
+// receipts. Use the same Tracer for all binds whose receipts are to
+// be matched to their messages.
+type Tracer struct {
+	// Provider of the tracer, the global one if nil.
+	Provider trace.TracerProvider
+
+	// MaxReceipts is the number of submitted messages waiting for
+	// their receipt, default 10000. The oldest are forgotten first.
+	MaxReceipts int
+
+	// RespTimeout is how long the span of a request waits for its
+	// response before it ends with smpp.ErrTimeout, default 1 minute.
+	RespTimeout time.Duration
+
+	mu       sync.Mutex
+	receipts map[string]*list.Element // of *receipt, by message id key.
+	order    list.List
+}
+
+// receipt is the span context of a message waiting for its receipt.
+type receipt struct {
+	key string // smpp.MessageIDKey of the message id.
+	sc  trace.SpanContext
+}
+
+// Wrap returns c wrapped by the tracer.
+func (t *Tracer) Wrap(c smpp.Conn) smpp.Conn {
+	return &conn{Conn: c, t: t, spans: make(map[uint32]*list.Element)}
+}
+
+func (t *Tracer) tracer() trace.Tracer {
+	tp := t.Provider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer("github.com/fiorix/go-smpp/v2/smpp/smpptrace")
+}
+
+func (t *Tracer) respTimeout() time.Duration {
+	if t.RespTimeout > 0 {
+		return t.RespTimeout
+	}
+	return time.Minute
+}
+
+// wait records the span context of the submitted message msgid. It is
+// stored by the key of msgid, so that receipts using another base for
+// the id find it.
+func (t *Tracer) wait(msgid string, sc trace.SpanContext) {
+	key := smpp.MessageIDKey(msgid)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.receipts == nil {
+		t.receipts = make(map[string]*list.Element)
+	}
+	if e, ok := t.receipts[key]; ok {
+		t.order.Remove(e)
+	}
+	t.receipts[key] = t.order.PushBack(&receipt{key: key, sc: sc})
+	max := t.MaxReceipts
+	if max == 0 {
+		max = 10000
+	}
+	for t.order.Len() > max {
+		e := t.order.Front()
+		t.order.Remove(e)
+		delete(t.receipts, e.Value.(*receipt).key)
+	}
+}
+
+// lookup returns the span context of the message of receipt r, and
+// forgets it if the receipt is final.
+func (t *Tracer) lookup(r *smpp.DeliveryReceipt) (trace.SpanContext, bool) {
+	key := smpp.MessageIDKey(r.ID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.receipts[key]
+	if !ok {
+		return trace.SpanContext{}, false
+	}
+	rc := e.Value.(*receipt)
+	if !r.Intermediate {
+		t.order.Remove(e)
+		delete(t.receipts, key)
+	}
+	return rc.sc, true
+}
+
+// conn is a Conn traced by a Tracer.
+type conn struct {
+	smpp.Conn
+	t *Tracer
+
+	mu    sync.Mutex
+	spans map[uint32]*list.Element // of *request, by sequence number.
+	order list.List                // of *request, oldest first.
+}
+
+// request is the span of a request waiting for its response.
+type request struct {
+	seq  uint32
+	span trace.Span
+	sent time.Time
+}
+
+// Write implements the smpp.Conn interface.
+func (c *conn) Write(p pdu.Body) error {
+	return c.WriteContext(context.Background(), p)
+}
+
+// WriteContext implements the smpp.ContextWriter interface.
+func (c *conn) WriteContext(ctx context.Context, p pdu.Body) error {
+	h := p.Header()
+	if h.ID&0x80000000 != 0 || h.ID == pdu.EnquireLinkID {
+		return smpp.WriteContext(ctx, c.Conn, p)
+	}
+	ctx, span := c.t.tracer().Start(ctx, h.ID.String(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrSeq.Int64(int64(h.Seq))))
+	if err := smpp.WriteContext(ctx, c.Conn, p); err != nil {
+		endErr(span, err)
+		return err
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(now)
+	if e, ok := c.spans[h.Seq]; ok {
+		c.order.Remove(e)
+		endErr(e.Value.(*request).span, errSeqReused)
+	}
+	c.spans[h.Seq] = c.order.PushBack(&request{seq: h.Seq, span: span, sent: now})
+	return nil
+}
+
+// expire ends the spans of the requests sent before now minus the
+// tracer's RespTimeout. It must be called with c.mu held.
+func (c *conn) expire(now time.Time) {
+	deadline := now.Add(-c.t.respTimeout())
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		req := e.Value.(*request)
+		if req.sent.After(deadline) {
+			return
+		}
+		c.order.Remove(e)
+		delete(c.spans, req.seq)
+		endErr(req.span, smpp.ErrTimeout)
+	}
+}
+
+// Read implements the smpp.Conn interface.
+func (c *conn) Read() (pdu.Body, error) {
+	p, err := c.Conn.Read()
+	if err != nil {
+		c.endAll(err)
+		return p, err
+	}
+	h := p.Header()
+	if h.ID&0x80000000 != 0 {
+		c.response(p)
+	} else if r, err := smpp.ParseDeliveryReceipt(p); err == nil {
+		c.receipt(p, r)
+	}
+	return p, nil
+}
+
+// response ends the span of the request of p.
+func (c *conn) response(p pdu.Body) {
+	h := p.Header()
+	c.mu.Lock()
+	e, ok := c.spans[h.Seq]
+	if ok {
+		c.order.Remove(e)
+		delete(c.spans, h.Seq)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+	span := e.Value.(*request).span
+	span.SetAttributes(AttrStatus.Int64(int64(h.Status)))
+	if h.Status != 0 {
+		span.SetStatus(codes.Error, h.Status.Error())
+	}
+	if f := p.Fields()[pdufield.MessageID]; f != nil && f.String() != "" {
+		span.SetAttributes(AttrMessageID.String(f.String()))
+		switch h.ID {
+		case pdu.SubmitSMRespID, pdu.SubmitMultiRespID, pdu.DataSMRespID:
+			if h.Status == 0 {
+				c.t.wait(f.String(), span.SpanContext())
+			}
+		}
+	}
+	span.End()
+}
+
+// receipt records the span of the delivery receipt r, in p.
+func (c *conn) receipt(p pdu.Body, r *smpp.DeliveryReceipt) {
+	sc, ok := c.t.lookup(r)
+	if !ok {
+		return
+	}
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sc)
+	_, span := c.t.tracer().Start(ctx, p.Header().ID.String()+" receipt",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			AttrSeq.Int64(int64(p.Header().Seq)),
+			AttrMessageID.String(r.ID),
+			AttrReceiptState.String(r.State),
+		))
+	span.End()
+}
+
+// endAll ends the spans of the requests waiting for a response when
+// the connection fails with err.
+func (c *conn) endAll(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		req := e.Value.(*request)
+		c.order.Remove(e)
+		delete(c.spans, req.seq)
+		endErr(req.span, err)
+	}
+}
+
+// endErr ends span with the error err.
+func endErr(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.End()
+}
@@ -0,0 +1,136 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpptrace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fiorix/go-smpp/v2/smpp"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+)
+
+func TestTracer(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, m pdu.Body) {
+		if m.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		resp := pdu.NewSubmitSMResp()
+		resp.Header().Seq = m.Header().Seq
+		resp.Fields().Set(pdufield.MessageID, "4d2")
+		c.Write(resp)
+		dr := pdu.NewDeliverSM()
+		f := dr.Fields()
+		f.Set(pdufield.ESMClass, 0x04)
+		f.Set(pdufield.ShortMessage, "id:1234 sub:001 dlvrd:001 stat:DELIVRD err:000")
+		c.Write(dr)
+	}
+	s.Start()
+	defer s.Close()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	receipts := make(chan *smpp.DeliveryReceipt, 1)
+	tc := &smpp.Transceiver{
+		Addr:           s.Addr(),
+		User:           smpptest.DefaultUser,
+		Passwd:         smpptest.DefaultPasswd,
+		WrapConn:       (&Tracer{Provider: tp}).Wrap,
+		ReceiptHandler: func(r *smpp.DeliveryReceipt) { receipts <- r },
+	}
+	defer tc.Close()
+	conn := <-tc.Bind()
+	if conn.Status() != smpp.Connected {
+		t.Fatal(conn.Error())
+	}
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err := tc.SubmitContext(ctx, &smpp.ShortMessage{
+		Src:      "root",
+		Dst:      "foobar",
+		Text:     pdutext.Raw("Lorem ipsum"),
+		Register: pdufield.FinalDeliveryReceipt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+	select {
+	case <-receipts:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for delivery receipt")
+	}
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range sr.Ended() {
+		spans[span.Name()] = span
+	}
+	submit, ok := spans[pdu.SubmitSMID.String()]
+	if !ok {
+		t.Fatalf("missing submit_sm span: %v", spans)
+	}
+	if submit.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("submit_sm span is not a child of the context span")
+	}
+	var msgid string
+	for _, kv := range submit.Attributes() {
+		if kv.Key == AttrMessageID {
+			msgid = kv.Value.AsString()
+		}
+	}
+	if msgid != "4d2" {
+		t.Fatalf("unexpected message id: %q", msgid)
+	}
+	receipt, ok := spans[pdu.DeliverSMID.String()+" receipt"]
+	if !ok {
+		t.Fatalf("missing receipt span: %v", spans)
+	}
+	if receipt.Parent().SpanID() != submit.SpanContext().SpanID() ||
+		receipt.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Fatal("receipt span is not a child of the submit_sm span")
+	}
+}
+
+// nopConn is a Conn that discards everything written to it.
+type nopConn struct{}
+
+func (nopConn) Read() (pdu.Body, error) { return nil, nil }
+func (nopConn) Write(pdu.Body) error    { return nil }
+func (nopConn) Close() error            { return nil }
+
+func TestTracerPendingSpans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	c := (&Tracer{Provider: tp, RespTimeout: 10 * time.Millisecond}).Wrap(nopConn{})
+	// A request with the same sequence number ends the first span.
+	p := pdu.NewSubmitSM(nil)
+	if err := c.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	ended := sr.Ended()
+	if len(ended) != 1 || ended[0].Status().Description != errSeqReused.Error() {
+		t.Fatalf("unexpected spans after reusing seq: %v", ended)
+	}
+	// The next request ends the span that timed out.
+	time.Sleep(20 * time.Millisecond)
+	if err := c.Write(pdu.NewSubmitSM(nil)); err != nil {
+		t.Fatal(err)
+	}
+	ended = sr.Ended()
+	if len(ended) != 2 || ended[1].Status().Description != smpp.ErrTimeout.Error() {
+		t.Fatalf("unexpected spans after timeout: %v", ended)
+	}
+	if n := len(c.(*conn).spans); n != 1 {
+		t.Fatalf("unexpected number of pending spans: want 1, have %d", n)
+	}
+}
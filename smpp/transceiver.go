@@ -8,28 +8,38 @@ import (
 	"crypto/tls"
 	"fmt"
 	"math/rand"
+	"sync/atomic"
 	"time"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
 )
 
 // Transceiver implements an SMPP transceiver.
 //
 // The API is a combination of the Transmitter and Receiver.
 type Transceiver struct {
-	Addr               string        // Server address in form of host:port.
-	User               string        // Username.
-	Passwd             string        // Password.
-	SystemType         string        // System type, default empty.
-	EnquireLink        time.Duration // Enquire link interval, default 10s.
-	EnquireLinkTimeout time.Duration // Time after last EnquireLink response when connection considered down
-	RespTimeout        time.Duration // Response timeout, default 1s.
-	BindInterval       time.Duration // Binding retry interval
-	TLS                *tls.Config   // TLS client settings, optional.
-	Handler            HandlerFunc   // Receiver handler, optional.
-	RateLimiter        RateLimiter   // Rate limiter, optional.
-	WindowSize         uint
+	Addr               string                // Server address in form of host:port.
+	User               string                // Username.
+	Passwd             string                // Password.
+	SystemType         string                // System type, default empty.
+	EnquireLink        time.Duration         // Enquire link interval, default 10s.
+	EnquireLinkTimeout time.Duration         // Time after last EnquireLink response when connection considered down
+	RespTimeout        time.Duration         // Response timeout, default 1s.
+	BindInterval       time.Duration         // Binding retry interval
+	TLS                *tls.Config           // TLS client settings, optional.
+	Handler            HandlerFunc           // Receiver handler, optional.
+	AlertHandler       AlertHandlerFunc      // Alert notification handler, optional.
+	ReceiptHandler     ReceiptHandlerFunc    // Delivery receipt handler, optional.
+	RateLimiter        RateLimiter           // Rate limiter, optional.
+	WindowSize         uint                  // Max requests waiting for a response, optional.
+	WindowWait         bool                  // Wait for a free slot instead of failing with ErrMaxWindowSize.
+	FlowControl        *FlowControl          // Adaptive flow control, optional.
+	RetryPolicy        RetryPolicy           // Retries failed requests, optional.
+	Metrics            Metrics               // Receives measurements, optional.
+	WrapConn           func(Conn) Conn       // Wraps new connections, e.g. with PDULogger, optional.
+	InterfaceVersion   uint8                 // Interface version requested on bind, default V34.
+	ConnStatusHandler  ConnStatusHandlerFunc // Called on every connection status change, optional.
 
 	Transmitter
 }
@@ -43,18 +53,24 @@ func (t *Transceiver) Bind() <-chan ConnStatus {
 		return t.cl.Status
 	}
 	t.tx.Lock()
-	t.tx.inflight = make(map[string]chan *tx)
+	t.tx.inflight = make(map[string]*pending)
 	t.tx.Unlock()
 	c := &client{
 		Addr:               t.Addr,
 		TLS:                t.TLS,
 		Status:             make(chan ConnStatus, 1),
+		StatusHandler:      t.ConnStatusHandler,
 		BindFunc:           t.bindFunc,
 		EnquireLink:        t.EnquireLink,
 		EnquireLinkTimeout: t.EnquireLinkTimeout,
 		RespTimeout:        t.RespTimeout,
 		WindowSize:         t.WindowSize,
+		WindowWait:         t.WindowWait,
 		RateLimiter:        t.RateLimiter,
+		FlowControl:        t.FlowControl,
+		RetryPolicy:        t.RetryPolicy,
+		Metrics:            t.Metrics,
+		WrapConn:           t.WrapConn,
 		BindInterval:       t.BindInterval,
 	}
 	t.cl.client = c
@@ -69,7 +85,7 @@ func (t *Transceiver) bindFunc(c Conn) error {
 	f.Set(pdufield.SystemID, t.User)
 	f.Set(pdufield.Password, t.Passwd)
 	f.Set(pdufield.SystemType, t.SystemType)
-	resp, err := bind(c, p)
+	resp, err := bind(c, p, t.InterfaceVersion)
 	if err != nil {
 		return err
 	}
@@ -77,6 +93,7 @@ func (t *Transceiver) bindFunc(c Conn) error {
 		return fmt.Errorf("unexpected response for BindTransceiver: %s",
 			resp.Header().ID)
 	}
-	go t.handlePDU(t.Handler)
+	atomic.StoreUint32(&t.version, uint32(negotiate(t.InterfaceVersion, resp)))
+	go t.handlePDU(t.Handler, t.AlertHandler, t.ReceiptHandler)
 	return nil
 }
@@ -5,6 +5,7 @@
 package smpp
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/binary"
 	"errors"
@@ -15,35 +16,64 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/wappush"
 )
 
 // ErrMaxWindowSize is returned when an operation (such as Submit) violates
 // the maximum window size configured for the Transmitter or Transceiver.
 var ErrMaxWindowSize = errors.New("reached max window size")
 
+// ErrNotSupported is returned on attempts to use features of an
+// interface version higher than the one negotiated with the SMSC,
+// such as broadcasts on a connection that did not negotiate V50.
+var ErrNotSupported = errors.New("not supported by the negotiated interface version")
+
+// GenericNackError is returned when the SMSC answers a request with
+// generic_nack, e.g. because it could not decode it.
+type GenericNackError struct {
+	Status pdu.Status // The command_status of the generic_nack.
+}
+
+// Error implements the error interface.
+func (e *GenericNackError) Error() string {
+	return "generic_nack: " + e.Status.Error()
+}
+
+// Unwrap returns the status of the generic_nack.
+func (e *GenericNackError) Unwrap() error { return e.Status }
+
 // MaxDestinationAddress is the maximum number of destination addresses allowed
 // in the submit_multi operation.
 const MaxDestinationAddress = 254
 
 // Transmitter implements an SMPP client transmitter.
 type Transmitter struct {
-	Addr               string        // Server address in form of host:port.
-	User               string        // Username.
-	Passwd             string        // Password.
-	SystemType         string        // System type, default empty.
-	EnquireLink        time.Duration // Enquire link interval, default 10s.
-	EnquireLinkTimeout time.Duration // Time after last EnquireLink response when connection considered down
-	RespTimeout        time.Duration // Response timeout, default 1s.
-	BindInterval       time.Duration // Binding retry interval
-	TLS                *tls.Config   // TLS client settings, optional.
-	RateLimiter        RateLimiter   // Rate limiter, optional.
-	WindowSize         uint
+	Addr               string                // Server address in form of host:port.
+	User               string                // Username.
+	Passwd             string                // Password.
+	SystemType         string                // System type, default empty.
+	EnquireLink        time.Duration         // Enquire link interval, default 10s.
+	EnquireLinkTimeout time.Duration         // Time after last EnquireLink response when connection considered down
+	RespTimeout        time.Duration         // Response timeout, default 1s.
+	BindInterval       time.Duration         // Binding retry interval
+	TLS                *tls.Config           // TLS client settings, optional.
+	RateLimiter        RateLimiter           // Rate limiter, optional.
+	WindowSize         uint                  // Max requests waiting for a response, optional.
+	WindowWait         bool                  // Wait for a free slot instead of failing with ErrMaxWindowSize.
+	FlowControl        *FlowControl          // Adaptive flow control, optional.
+	RetryPolicy        RetryPolicy           // Retries failed requests, optional.
+	Metrics            Metrics               // Receives measurements, optional.
+	WrapConn           func(Conn) Conn       // Wraps new connections, e.g. with PDULogger, optional.
+	InterfaceVersion   uint8                 // Interface version requested on bind, default V34.
+	ConnStatusHandler  ConnStatusHandlerFunc // Called on every connection status change, optional.
 	rMutex             sync.Mutex
 	r                  *rand.Rand
+	version            uint32 // negotiated interface version, atomic.
 
 	cl struct {
 		sync.Mutex
@@ -51,9 +81,10 @@ type Transmitter struct {
 	}
 
 	tx struct {
-		count int32
 		sync.Mutex
-		inflight map[string]chan *tx
+		inflight map[string]*pending
+		closing  bool          // set by Shutdown.
+		drained  chan struct{} // closed when inflight gets empty while closing.
 	}
 }
 
@@ -74,18 +105,24 @@ func (t *Transmitter) Bind() <-chan ConnStatus {
 		return t.cl.Status
 	}
 	t.tx.Lock()
-	t.tx.inflight = make(map[string]chan *tx)
+	t.tx.inflight = make(map[string]*pending)
 	t.tx.Unlock()
 	c := &client{
 		Addr:               t.Addr,
 		TLS:                t.TLS,
 		Status:             make(chan ConnStatus, 1),
+		StatusHandler:      t.ConnStatusHandler,
 		BindFunc:           t.bindFunc,
 		EnquireLink:        t.EnquireLink,
 		EnquireLinkTimeout: t.EnquireLinkTimeout,
 		RespTimeout:        t.RespTimeout,
 		WindowSize:         t.WindowSize,
+		WindowWait:         t.WindowWait,
 		RateLimiter:        t.RateLimiter,
+		FlowControl:        t.FlowControl,
+		RetryPolicy:        t.RetryPolicy,
+		Metrics:            t.Metrics,
+		WrapConn:           t.WrapConn,
 		BindInterval:       t.BindInterval,
 	}
 	t.cl.client = c
@@ -100,7 +137,7 @@ func (t *Transmitter) bindFunc(c Conn) error {
 	f.Set(pdufield.SystemID, t.User)
 	f.Set(pdufield.Password, t.Passwd)
 	f.Set(pdufield.SystemType, t.SystemType)
-	resp, err := bind(c, p)
+	resp, err := bind(c, p, t.InterfaceVersion)
 	if err != nil {
 		return err
 	}
@@ -108,38 +145,72 @@ func (t *Transmitter) bindFunc(c Conn) error {
 		return fmt.Errorf("unexpected response for BindTransmitter: %s",
 			resp.Header().ID)
 	}
-	go t.handlePDU(nil)
+	atomic.StoreUint32(&t.version, uint32(negotiate(t.InterfaceVersion, resp)))
+	go t.handlePDU(nil, nil, nil)
 	return nil
 }
 
-// f is only set on transceiver.
-func (t *Transmitter) handlePDU(f HandlerFunc) {
+// NegotiatedVersion returns the interface version negotiated with the
+// SMSC on the last bind, or 0 if not bound yet.
+func (t *Transmitter) NegotiatedVersion() uint8 {
+	return uint8(atomic.LoadUint32(&t.version))
+}
+
+// f and af are only set on transceiver.
+func (t *Transmitter) handlePDU(f HandlerFunc, af AlertHandlerFunc, rf ReceiptHandlerFunc) {
 	for {
 		p, err := t.cl.Read()
 		if err != nil || p == nil {
 			break
 		}
-		key := p.Header().Key()
+		h := p.Header()
 		t.tx.Lock()
-		rc := t.tx.inflight[key]
+		var pd *pending
+		if h.ID == pdu.GenericNACKID {
+			pd = t.nacked(h.Seq)
+		} else {
+			pd = t.tx.inflight[h.Key()]
+		}
 		t.tx.Unlock()
-		if rc != nil {
-			rc <- &tx{PDU: p}
+		if pd != nil && h.ID == pdu.GenericNACKID {
+			pd.rc <- &tx{Err: &GenericNackError{Status: h.Status}}
+		} else if pd != nil {
+			pd.rc <- &tx{PDU: p}
+		} else if af != nil && p.Header().ID == pdu.AlertNotificationID {
+			af(newAlert(p))
+		} else if dr := parseReceipt(rf, p); dr != nil {
+			rf(dr)
 		} else if f != nil {
 			f(p)
 		}
-		if p.Header().ID == pdu.DeliverSMID { // Send DeliverSMResp
+		switch p.Header().ID {
+		case pdu.DeliverSMID: // Send DeliverSMResp
 			pResp := pdu.NewDeliverSMRespSeq(p.Header().Seq)
 			t.cl.Write(pResp)
+		case pdu.DataSMID: // Send DataSMResp
+			pResp := pdu.NewDataSMRespSeq(p.Header().Seq)
+			t.cl.Write(pResp)
 		}
 	}
 	t.tx.Lock()
-	for _, rc := range t.tx.inflight {
-		rc <- &tx{Err: ErrNotConnected}
+	for _, pd := range t.tx.inflight {
+		pd.rc <- &tx{Err: ErrNotConnected}
 	}
 	t.tx.Unlock()
 }
 
+// nacked returns the request in flight with the given sequence number,
+// answered with generic_nack, or nil. Unlike responses, generic_nack
+// does not match the command of the request. The caller must hold t.tx.
+func (t *Transmitter) nacked(seq uint32) *pending {
+	for _, pd := range t.tx.inflight {
+		if pd.p.Header().Seq == seq {
+			return pd
+		}
+	}
+	return nil
+}
+
 // Close implements the ClientConn interface.
 func (t *Transmitter) Close() error {
 	t.cl.Lock()
@@ -150,6 +221,99 @@ func (t *Transmitter) Close() error {
 	return t.cl.Close()
 }
 
+// State returns the last connection status of the Transmitter, for
+// health checks, or 0 if Bind was not called.
+func (t *Transmitter) State() ConnStatusID {
+	t.cl.Lock()
+	defer t.cl.Unlock()
+	if t.cl.client == nil {
+		return 0
+	}
+	return t.cl.State()
+}
+
+// ShutdownError is returned by Shutdown when its context is done before
+// the responses of all requests in flight arrive.
+type ShutdownError struct {
+	Unacked []pdu.Body // Requests left without a response.
+	Err     error      // The error of the context.
+}
+
+// Error implements the error interface.
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("shutdown: %d requests unacknowledged: %v", len(e.Unacked), e.Err)
+}
+
+// Unwrap returns the error of the context.
+func (e *ShutdownError) Unwrap() error { return e.Err }
+
+// Shutdown gracefully terminates the connection. New requests fail with
+// ErrShutdown, while Shutdown waits for the responses of the requests in
+// flight. It then sends Unbind and waits for UnbindResp before closing
+// the connection, returning ctx.Err() if ctx is done first.
+//
+// If ctx is done while requests are still waiting for their response,
+// Shutdown closes the connection without waiting for UnbindResp and
+// returns a *ShutdownError with those requests. They fail with
+// ErrNotConnected.
+func (t *Transmitter) Shutdown(ctx context.Context) error {
+	t.cl.Lock()
+	c := t.cl.client
+	t.cl.Unlock()
+	if c == nil {
+		return ErrNotConnected
+	}
+	t.tx.Lock()
+	t.tx.closing = true
+	var drained chan struct{}
+	if len(t.tx.inflight) > 0 {
+		drained = make(chan struct{})
+		t.tx.drained = drained
+	}
+	t.tx.Unlock()
+	if drained != nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			var unacked []pdu.Body
+			t.tx.Lock()
+			for _, pd := range t.tx.inflight {
+				unacked = append(unacked, pd.p)
+			}
+			t.tx.Unlock()
+			c.shutdown(ctx)
+			if len(unacked) > 0 {
+				return &ShutdownError{Unacked: unacked, Err: ctx.Err()}
+			}
+			return ctx.Err()
+		}
+	}
+	return c.shutdown(ctx)
+}
+
+// WindowStatus returns the current occupancy of the window. Queued
+// callers are only reported when WindowWait is set.
+func (t *Transmitter) WindowStatus() WindowStatus {
+	t.cl.Lock()
+	defer t.cl.Unlock()
+	if t.cl.client == nil {
+		return WindowStatus{Size: int(t.WindowSize)}
+	}
+	return t.cl.window.status()
+}
+
+// FlowStatus returns the current state of the flow control, or the
+// zero FlowStatus if FlowControl is not set or the Transmitter is not
+// bound.
+func (t *Transmitter) FlowStatus() FlowStatus {
+	t.cl.Lock()
+	defer t.cl.Unlock()
+	if t.cl.client == nil || t.cl.flow == nil {
+		return FlowStatus{}
+	}
+	return t.cl.flow.status()
+}
+
 // UnsucessDest contains information about unsuccessful delivery to an address
 // when submit multi is used
 type UnsucessDest struct {
@@ -181,6 +345,19 @@ type ShortMessage struct {
 	Validity time.Duration
 	Register pdufield.DeliverySetting
 
+	// UDH holds information elements for the user data header, e.g.
+	// application port addressing. The UDHI flag of esm_class is set
+	// when there are any.
+	UDH *pdufield.UDHList
+
+	// Application port addressing, e.g. WAP Push. Ports are sent in
+	// the user data header (IEI 0x05), or in the source_port and
+	// destination_port TLVs if PortTLVs is set. Messages have no ports
+	// if DstPort is 0.
+	SrcPort  uint16
+	DstPort  uint16
+	PortTLVs bool
+
 	// Other fields, normally optional.
 	TLVFields            pdutlv.Fields
 	ServiceType          string
@@ -245,6 +422,41 @@ func (sm *ShortMessage) NumbUnsuccess() (int, error) {
 	return i, nil
 }
 
+// clone returns a copy of sm that carries the current response PDU
+// under its own lock.
+func (sm *ShortMessage) clone() ShortMessage {
+	return ShortMessage{
+		Src:                  sm.Src,
+		Dst:                  sm.Dst,
+		DstList:              sm.DstList,
+		DLs:                  sm.DLs,
+		Text:                 sm.Text,
+		Validity:             sm.Validity,
+		Register:             sm.Register,
+		UDH:                  sm.UDH,
+		SrcPort:              sm.SrcPort,
+		DstPort:              sm.DstPort,
+		PortTLVs:             sm.PortTLVs,
+		TLVFields:            sm.TLVFields,
+		ServiceType:          sm.ServiceType,
+		SourceAddrTON:        sm.SourceAddrTON,
+		SourceAddrNPI:        sm.SourceAddrNPI,
+		DestAddrTON:          sm.DestAddrTON,
+		DestAddrNPI:          sm.DestAddrNPI,
+		ESMClass:             sm.ESMClass,
+		ProtocolID:           sm.ProtocolID,
+		PriorityFlag:         sm.PriorityFlag,
+		ScheduleDeliveryTime: sm.ScheduleDeliveryTime,
+		ReplaceIfPresentFlag: sm.ReplaceIfPresentFlag,
+		SMDefaultMsgID:       sm.SMDefaultMsgID,
+		NumberDests:          sm.NumberDests,
+		resp: struct {
+			sync.Mutex
+			p pdu.Body
+		}{p: sm.Resp()},
+	}
+}
+
 // UnsuccessSmes returns a list with the SME address(es) or/and Distribution List names to
 // which submission was unsuccessful and the respective errors, when submit multi is used.
 // Returns nil and an error if the response PDU is not available, or does
@@ -270,102 +482,377 @@ func (sm *ShortMessage) UnsuccessSmes() ([]UnsucessDest, error) {
 	return nil, errors.New("Cannot convert PDU field to UnSmeList")
 }
 
-func (t *Transmitter) do(p pdu.Body) (*tx, error) {
+// do sends the given PDU and waits for its response. It returns
+// ctx.Err() if ctx is done before the PDU is sent or the response
+// arrives, and ErrTimeout if the response takes longer than the
+// configured RespTimeout.
+//
+// Failed requests are sent again as decided by the RetryPolicy, if
+// any. Otherwise, with FlowControl, requests rejected with a throttling
+// status are sent again after its back-off, up to MaxRetries times.
+// Every attempt has a new sequence number.
+func (t *Transmitter) do(ctx context.Context, p pdu.Body) (*tx, error) {
+	t.cl.Lock()
+	var rp RetryPolicy
+	if t.cl.client != nil {
+		rp = t.cl.RetryPolicy
+	}
+	t.cl.Unlock()
+	for attempts := 1; ; attempts++ {
+		resp, err := t.try(ctx, p, attempts-1, rp == nil)
+		rerr := err
+		if err == nil && resp.PDU.Header().Status != 0 {
+			rerr = resp.PDU.Header().Status
+		}
+		if rerr == nil || rp == nil {
+			return resp, err
+		}
+		d, ok := rp.Retry(attempts, rerr)
+		if !ok {
+			return resp, err
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		p.Header().Seq = pdu.NextSeq()
+	}
+}
+
+// try sends the given PDU and waits for its response. If flowRetry is
+// set, it sends the PDU again while the flow control retries it. The
+// PDU was sent the given number of times before, which sets the
+// back-off of the flow control.
+func (t *Transmitter) try(ctx context.Context, p pdu.Body, retries int, flowRetry bool) (*tx, error) {
+	for ; ; retries++ {
+		pd, err := t.send(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		pd.retries = retries
+		resp, err := pd.wait(ctx)
+		if !pd.retry || !flowRetry {
+			return resp, err
+		}
+		p.Header().Seq = pdu.NextSeq()
+	}
+}
+
+// pending is a request that has been sent and is waiting for its
+// response. It holds a window slot and an entry in the inflight map
+// until wait returns.
+type pending struct {
+	t    *Transmitter
+	p    pdu.Body
+	key  string
+	rc   chan *tx
+	flow *flow // nil without FlowControl.
+	id   pdu.ID
+	sent time.Time
+
+	retries int  // times the request was retried.
+	retry   bool // throttled, may be retried.
+}
+
+// send acquires a window slot, waits for the flow control if any,
+// registers p in the inflight map and writes it to the connection.
+func (t *Transmitter) send(ctx context.Context, p pdu.Body) (*pending, error) {
 	t.cl.Lock()
 	notbound := t.cl.client == nil
 	t.cl.Unlock()
 	if notbound {
 		return nil, ErrNotBound
 	}
-	if t.cl.WindowSize > 0 {
-		inflight := uint(atomic.AddInt32(&t.tx.count, 1))
-		defer func(t *Transmitter) { atomic.AddInt32(&t.tx.count, -1) }(t)
-		if inflight > t.cl.WindowSize {
-			return nil, ErrMaxWindowSize
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := t.cl.window.acquire(ctx); err != nil {
+		return nil, err
+	}
+	t.windowMetrics()
+	pd := &pending{t: t, p: p, key: p.Header().Key(), rc: make(chan *tx, 1), flow: t.cl.flow, id: p.Header().ID}
+	if pd.flow != nil {
+		if err := pd.flow.wait(ctx); err != nil {
+			t.cl.window.release()
+			return nil, err
 		}
 	}
-	rc := make(chan *tx, 1)
-	key := p.Header().Key()
 	t.tx.Lock()
-	t.tx.inflight[key] = rc
-	t.tx.Unlock()
-	defer func() {
-		t.tx.Lock()
-		delete(t.tx.inflight, key)
+	if t.tx.closing {
 		t.tx.Unlock()
-	}()
-	err := t.cl.Write(p)
-	if err != nil {
+		t.cl.window.release()
+		t.windowMetrics()
+		return nil, ErrShutdown
+	}
+	t.tx.inflight[pd.key] = pd
+	t.tx.Unlock()
+	if err := t.cl.WriteContext(ctx, p); err != nil {
+		pd.done()
 		return nil, err
 	}
+	pd.sent = time.Now()
+	return pd, nil
+}
+
+// wait waits for the response of the pending request.
+func (pd *pending) wait(ctx context.Context) (*tx, error) {
+	defer pd.done()
 	select {
-	case resp := <-rc:
+	case resp := <-pd.rc:
 		if resp.Err != nil {
 			return nil, resp.Err
 		}
+		if pd.flow != nil {
+			pd.retry = pd.flow.observe(resp.PDU, pd.retries)
+		}
+		if m := pd.t.cl.Metrics; m != nil {
+			m.Latency(pd.id, time.Since(pd.sent))
+		}
 		return resp, nil
-	case <-t.cl.respTimeout():
+	case <-pd.t.cl.respTimeout():
+		if m := pd.t.cl.Metrics; m != nil {
+			m.Timeout(pd.id)
+		}
 		return nil, ErrTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// done removes the request from the inflight map and releases its
+// window slot.
+func (pd *pending) done() {
+	pd.t.tx.Lock()
+	delete(pd.t.tx.inflight, pd.key)
+	if pd.t.tx.drained != nil && len(pd.t.tx.inflight) == 0 {
+		close(pd.t.tx.drained)
+		pd.t.tx.drained = nil
+	}
+	pd.t.tx.Unlock()
+	pd.t.cl.window.release()
+	pd.t.windowMetrics()
+}
+
+// windowMetrics reports the occupancy of the window to Metrics.
+func (t *Transmitter) windowMetrics() {
+	if m := t.cl.Metrics; m != nil {
+		m.Window(t.cl.window.status())
 	}
 }
 
 // Submit sends a short message and returns and updates the given
 // sm with the response status. It returns the same sm object.
 func (t *Transmitter) Submit(sm *ShortMessage) (*ShortMessage, error) {
+	return t.SubmitContext(context.Background(), sm)
+}
+
+// SubmitContext is like Submit but honours the deadline and
+// cancellation of ctx while waiting for the RateLimiter and for
+// the response, returning ctx.Err() when ctx is done first.
+func (t *Transmitter) SubmitContext(ctx context.Context, sm *ShortMessage) (*ShortMessage, error) {
+	p, err := newSubmitPDU(sm)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.do(ctx, p)
+	return sm.update(resp, err, p.Header().ID|0x80000000)
+}
+
+// SubmitAsync sends a short message without waiting for its response,
+// allowing a single goroutine to pipeline many messages. It blocks only
+// while waiting for the RateLimiter and, if WindowWait is set, for a
+// free slot in the window. ctx applies to those waits and the send
+// only; the response is waited for up to RespTimeout, even if ctx is
+// done after SubmitAsync returns.
+//
+// Once the response arrives, or the request fails or times out, fn is
+// called from a separate goroutine with the given sm updated with the
+// response, exactly as returned by Submit. If the message cannot be
+// sent, SubmitAsync returns the error and fn is not called.
+// Failed messages are not retried, regardless of the RetryPolicy.
+func (t *Transmitter) SubmitAsync(ctx context.Context, sm *ShortMessage, fn func(sm *ShortMessage, err error)) error {
+	p, err := newSubmitPDU(sm)
+	if err != nil {
+		return err
+	}
+	pd, err := t.send(ctx, p)
+	if err != nil {
+		return err
+	}
+	go func() {
+		resp, err := pd.wait(context.Background())
+		fn(sm.update(resp, err, p.Header().ID|0x80000000))
+	}()
+	return nil
+}
+
+// newSubmitPDU returns a submit_sm PDU for sm, or submit_multi if sm
+// has a list of destinations.
+func newSubmitPDU(sm *ShortMessage) (pdu.Body, error) {
 	if len(sm.DstList) > 0 || len(sm.DLs) > 0 {
 		// if we have a single destination address add it to the list
 		if sm.Dst != "" {
 			sm.DstList = append(sm.DstList, sm.Dst)
 		}
 		p := pdu.NewSubmitMulti(sm.TLVFields)
-		return t.submitMsgMulti(sm, p, uint8(sm.Text.Type()))
+		return p, setSubmitMultiFields(sm, p, uint8(sm.Text.Type()))
 	}
 	p := pdu.NewSubmitSM(sm.TLVFields)
-	return t.submitMsg(sm, p, uint8(sm.Text.Type()))
+	setSubmitFields(sm, p, uint8(sm.Text.Type()))
+	return p, nil
+}
+
+// update sets the response PDU of sm and returns sm with the error
+// status of the response, if any.
+func (sm *ShortMessage) update(resp *tx, err error, want pdu.ID) (*ShortMessage, error) {
+	if err != nil {
+		return nil, err
+	}
+	sm.resp.Lock()
+	sm.resp.p = resp.PDU
+	sm.resp.Unlock()
+	if resp.PDU == nil {
+		return nil, fmt.Errorf("unexpected empty PDU")
+	}
+	if id := resp.PDU.Header().ID; id != want {
+		return sm, fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return sm, s
+	}
+	return sm, resp.Err
 }
 
 // SubmitLongMsg sends a long message (more than 140 bytes)
 // and returns and updates the given sm with the response status.
 // It returns the same sm object.
 func (t *Transmitter) SubmitLongMsg(sm *ShortMessage) ([]ShortMessage, error) {
+	return t.SubmitLongMsgContext(context.Background(), sm)
+}
+
+// SubmitLongMsgContext is like SubmitLongMsg but honours the deadline
+// and cancellation of ctx for every part. If ctx is done before all
+// parts are sent, it returns the parts sent so far and ctx.Err().
+func (t *Transmitter) SubmitLongMsgContext(ctx context.Context, sm *ShortMessage) ([]ShortMessage, error) {
 	maxLen := 133 // 140-7 (UDH with 2 byte reference number)
 	switch sm.Text.(type) {
-	case pdutext.GSM7:
-		maxLen = 152 // to avoid an escape character being split between payloads
+	case pdutext.GSM7, pdutext.GSM7National:
+		maxLen = pdutext.MaxGSM7SegmentLen // to avoid an escape character being split between payloads
 		break
 	case pdutext.GSM7Packed:
-		maxLen = 132 // to avoid an escape character being split between payloads
-		break
+		return t.submitParts(ctx, sm, splitGSM7Packed(sm), uint8(sm.Text.Type()))
 	case pdutext.UCS2:
-		maxLen = 132 // to avoid a character being split between payloads
+		maxLen = 2 * pdutext.MaxUCS2SegmentLen // to avoid a character being split between payloads
 		break
 	}
+	if udh := userDataHeader(sm); udh != nil {
+		n := udh.Len()
+		switch sm.Text.(type) {
+		case pdutext.GSM7, pdutext.GSM7National:
+			n = (n*8 + 6) / 7 // septets taken by the information elements
+		case pdutext.UCS2:
+			n += n % 2 // to keep characters aligned
+		}
+		maxLen -= n
+	}
 	rawMsg := sm.Text.Encode()
 	countParts := int((len(rawMsg)-1)/maxLen) + 1
+	msgs := make([]pdutext.Codec, countParts)
+	for i := range msgs {
+		if i != countParts-1 {
+			msgs[i] = pdutext.Raw(rawMsg[i*maxLen : (i+1)*maxLen])
+		} else {
+			msgs[i] = pdutext.Raw(rawMsg[i*maxLen:])
+		}
+	}
+	return t.submitParts(ctx, sm, msgs, uint8(sm.Text.Type()))
+}
 
+// SubmitText sends text to the destination of sm, choosing the data
+// coding and splitting text in a long message as needed. The Text of
+// sm is ignored. If national languages are given, their GSM 7-bit
+// shift tables are considered too. See pdutext.NewPlan for details.
+//
+// It returns the short messages sent, a single one if text fits in
+// one short message.
+func (t *Transmitter) SubmitText(sm *ShortMessage, text string, langs ...encoding.Language) ([]ShortMessage, error) {
+	return t.SubmitTextContext(context.Background(), sm, text, langs...)
+}
+
+// SubmitTextContext is like SubmitText but honours the deadline and
+// cancellation of ctx for every part. If ctx is done before all parts
+// are sent, it returns the parts sent so far and ctx.Err().
+func (t *Transmitter) SubmitTextContext(ctx context.Context, sm *ShortMessage, text string, langs ...encoding.Language) ([]ShortMessage, error) {
+	var ieLen int
+	for _, ie := range headerIEs(sm) {
+		ieLen += ie.Len()
+	}
+	plan := pdutext.NewPlanWithHeader(text, ieLen, langs...)
+	sm.Text = plan.Codec
+	if len(plan.Segments) == 1 {
+		if _, err := t.SubmitContext(ctx, sm); err != nil {
+			return nil, err
+		}
+		return []ShortMessage{sm.clone()}, nil
+	}
+	msgs := make([]pdutext.Codec, len(plan.Segments))
+	for i, seg := range plan.Segments {
+		msgs[i] = pdutext.Raw(seg.Encode())
+	}
+	return t.submitParts(ctx, sm, msgs, uint8(plan.Codec.Type()))
+}
+
+// SubmitWAPPush sends push, a WSP push PDU such as those of package
+// wappush, as 8-bit data to the WAP Push port of the destination of sm,
+// splitting it in a long message as needed. The Text and ports of sm
+// are replaced.
+//
+// It returns the short messages sent, a single one if push fits in
+// one short message.
+func (t *Transmitter) SubmitWAPPush(sm *ShortMessage, push []byte) ([]ShortMessage, error) {
+	return t.SubmitWAPPushContext(context.Background(), sm, push)
+}
+
+// SubmitWAPPushContext is like SubmitWAPPush but honours the deadline
+// and cancellation of ctx for every part. If ctx is done before all
+// parts are sent, it returns the parts sent so far and ctx.Err().
+func (t *Transmitter) SubmitWAPPushContext(ctx context.Context, sm *ShortMessage, push []byte) ([]ShortMessage, error) {
+	sm.Text = pdutext.Binary2(push)
+	sm.SrcPort, sm.DstPort = wappush.OriginPort, wappush.Port
+	n := len(push)
+	if udh := userDataHeader(sm); udh != nil {
+		n += len(udh.Header())
+	}
+	if n > 140 {
+		return t.SubmitLongMsgContext(ctx, sm)
+	}
+	if _, err := t.SubmitContext(ctx, sm); err != nil {
+		return nil, err
+	}
+	return []ShortMessage{sm.clone()}, nil
+}
+
+// submitParts sends the parts of a long message, each with a user data
+// header with a 16-bit reference number followed by the information
+// elements of sm.
+func (t *Transmitter) submitParts(ctx context.Context, sm *ShortMessage, msgs []pdutext.Codec, dataCoding uint8) ([]ShortMessage, error) {
+	countParts := len(msgs)
 	parts := make([]ShortMessage, 0, countParts)
 
 	t.rMutex.Lock()
 	rn := uint16(t.r.Intn(0xFFFF))
 	t.rMutex.Unlock()
-	UDHHeader := make([]byte, 7)
-	UDHHeader[0] = 0x06              // length of user data header
-	UDHHeader[1] = 0x08              // information element identifier, CSMS 16 bit reference number
-	UDHHeader[2] = 0x04              // length of remaining header
-	UDHHeader[3] = uint8(rn >> 8)    // most significant byte of the reference number
-	UDHHeader[4] = uint8(rn)         // least significant byte of the reference number
-	UDHHeader[5] = uint8(countParts) // total number of message parts
-	for i := 0; i < countParts; i++ {
-		UDHHeader[6] = uint8(i + 1) // current message part
+	for i, msg := range msgs {
+		udh := userDataHeader(sm, pdufield.NewConcat16IE(rn, uint8(countParts), uint8(i+1)))
 		p := pdu.NewSubmitSM(sm.TLVFields)
 		f := p.Fields()
 		f.Set(pdufield.SourceAddr, sm.Src)
 		f.Set(pdufield.DestinationAddr, sm.Dst)
-		if i != countParts-1 {
-			f.Set(pdufield.ShortMessage, pdutext.Raw(append(UDHHeader, rawMsg[i*maxLen:(i+1)*maxLen]...)))
-		} else {
-			f.Set(pdufield.ShortMessage, pdutext.Raw(append(UDHHeader, rawMsg[i*maxLen:]...)))
-		}
+		f.Set(pdufield.ShortMessage, pdutext.Raw(udh.UserData(msg)))
+		setPortTLVs(p, sm)
 		f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
 		if sm.Validity != time.Duration(0) {
 			f.Set(pdufield.ValidityPeriod, convertValidity(sm.Validity))
@@ -381,10 +868,10 @@ func (t *Transmitter) SubmitLongMsg(sm *ShortMessage) ([]ShortMessage, error) {
 		f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
 		f.Set(pdufield.ReplaceIfPresentFlag, sm.ReplaceIfPresentFlag)
 		f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
-		f.Set(pdufield.DataCoding, uint8(sm.Text.Type()))
-		resp, err := t.do(p)
+		f.Set(pdufield.DataCoding, dataCoding)
+		resp, err := t.do(ctx, p)
 		if err != nil {
-			return nil, err
+			return parts, err
 		}
 		sm.resp.Lock()
 		sm.resp.p = resp.PDU
@@ -401,16 +888,100 @@ func (t *Transmitter) SubmitLongMsg(sm *ShortMessage) ([]ShortMessage, error) {
 		if resp.Err != nil {
 			return parts, resp.Err
 		}
-		parts = append(parts, *sm)
+		parts = append(parts, sm.clone())
 	}
 	return parts, nil
 }
 
-func (t *Transmitter) submitMsg(sm *ShortMessage, p pdu.Body, dataCoding uint8) (*ShortMessage, error) {
+// splitGSM7Packed splits the GSM 7-bit packed text of sm in the parts
+// of a long message. Parts are split by septets, leaving room for the
+// user data header of submitParts and its fill bits, and never between
+// an escape septet and the character it escapes.
+func splitGSM7Packed(sm *ShortMessage) []pdutext.Codec {
+	h := userDataHeader(sm, pdufield.NewConcat16IE(0, 0, 0)).Header()
+	n := 160 - (len(h)*8+6)/7
+	septets := pdutext.GSM7(sm.Text.(pdutext.GSM7Packed)).Encode()
+	var parts []pdutext.Codec
+	for len(septets) > 0 {
+		m := n
+		if m >= len(septets) {
+			m = len(septets)
+		} else if septets[m-1] == 0x1B {
+			m--
+		}
+		parts = append(parts, pdutext.GSM7Packed(pdutext.GSM7(septets[:m]).Decode()))
+		septets = septets[m:]
+	}
+	if len(parts) == 0 {
+		parts = append(parts, sm.Text)
+	}
+	return parts
+}
+
+// headerIEs returns the information elements of the ports of sm and
+// of sm.UDH.
+func headerIEs(sm *ShortMessage) []pdufield.UDH {
+	var ies []pdufield.UDH
+	if sm.DstPort != 0 && !sm.PortTLVs {
+		ies = append(ies, pdufield.NewPort16IE(sm.DstPort, sm.SrcPort))
+	}
+	if sm.UDH != nil {
+		ies = append(ies, sm.UDH.Data...)
+	}
+	return ies
+}
+
+// setPortTLVs sets the source_port and destination_port TLVs of p, if
+// sm has ports and sends them in TLVs.
+func setPortTLVs(p pdu.Body, sm *ShortMessage) {
+	if sm.DstPort == 0 || !sm.PortTLVs {
+		return
+	}
+	tlv := p.TLVFields()
+	tlv.Set(pdutlv.TagSourcePort, pdutlv.NewUint16(pdutlv.TagSourcePort, sm.SrcPort))
+	tlv.Set(pdutlv.TagDestinationPort, pdutlv.NewUint16(pdutlv.TagDestinationPort, sm.DstPort))
+}
+
+// userDataHeader returns the user data header of sm, with the given
+// information elements followed by those of its ports, sm.UDH and its
+// codec, or nil if there are none.
+func userDataHeader(sm *ShortMessage, ies ...pdufield.UDH) *pdufield.UDHList {
+	ies = append(ies, headerIEs(sm)...)
+	if hc, ok := sm.Text.(pdutext.HeaderCodec); ok {
+		h, _ := pdufield.ParseIEs(hc.Header())
+		ies = append(ies, h...)
+	}
+	if len(ies) == 0 {
+		return nil
+	}
+	return pdufield.NewUDHList(ies...)
+}
+
+// setShortMessage sets the short message of sm, prefixed with its user
+// data header, if any.
+func setShortMessage(f pdufield.Map, sm *ShortMessage) {
+	udh := userDataHeader(sm)
+	if udh == nil {
+		f.Set(pdufield.ShortMessage, sm.Text)
+		return
+	}
+	f.Set(pdufield.ShortMessage, udh.UserData(sm.Text))
+}
+
+// esmClass returns the esm_class of sm, with the UDHI flag set if sm
+// has a user data header.
+func esmClass(sm *ShortMessage) uint8 {
+	if userDataHeader(sm) != nil {
+		return sm.ESMClass | 0x40
+	}
+	return sm.ESMClass
+}
+
+func setSubmitFields(sm *ShortMessage, p pdu.Body, dataCoding uint8) {
 	f := p.Fields()
 	f.Set(pdufield.SourceAddr, sm.Src)
 	f.Set(pdufield.DestinationAddr, sm.Dst)
-	f.Set(pdufield.ShortMessage, sm.Text)
+	setShortMessage(f, sm)
 	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
 	// Check if the message has validity set.
 	if sm.Validity != time.Duration(0) {
@@ -421,36 +992,48 @@ func (t *Transmitter) submitMsg(sm *ShortMessage, p pdu.Body, dataCoding uint8)
 	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
 	f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
 	f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
-	f.Set(pdufield.ESMClass, sm.ESMClass)
+	f.Set(pdufield.ESMClass, esmClass(sm))
+	setPortTLVs(p, sm)
 	f.Set(pdufield.ProtocolID, sm.ProtocolID)
 	f.Set(pdufield.PriorityFlag, sm.PriorityFlag)
 	f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
 	f.Set(pdufield.ReplaceIfPresentFlag, sm.ReplaceIfPresentFlag)
 	f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
 	f.Set(pdufield.DataCoding, dataCoding)
-	resp, err := t.do(p)
-	if err != nil {
-		return nil, err
-	}
-	sm.resp.Lock()
-	sm.resp.p = resp.PDU
-	sm.resp.Unlock()
-	if resp.PDU == nil {
-		return nil, fmt.Errorf("unexpected empty PDU")
-	}
-	if id := resp.PDU.Header().ID; id != pdu.SubmitSMRespID {
-		return sm, fmt.Errorf("unexpected PDU ID: %s", id)
-	}
-	if s := resp.PDU.Header().Status; s != 0 {
-		return sm, s
-	}
-	return sm, resp.Err
 }
 
-func (t *Transmitter) submitMsgMulti(sm *ShortMessage, p pdu.Body, dataCoding uint8) (*ShortMessage, error) {
+// SubmitData sends a message using the data_sm operation and returns
+// and updates the given sm with the response status. The text is
+// carried in the message_payload TLV rather than short_message, so
+// there is no 254 octet limit. It returns the same sm object.
+func (t *Transmitter) SubmitData(sm *ShortMessage) (*ShortMessage, error) {
+	return t.SubmitDataContext(context.Background(), sm)
+}
+
+// SubmitDataContext is like SubmitData but honours the deadline and
+// cancellation of ctx, returning ctx.Err() when ctx is done first.
+func (t *Transmitter) SubmitDataContext(ctx context.Context, sm *ShortMessage) (*ShortMessage, error) {
+	p := pdu.NewDataSM(sm.TLVFields)
+	f := p.Fields()
+	f.Set(pdufield.ServiceType, sm.ServiceType)
+	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, sm.Src)
+	f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
+	f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
+	f.Set(pdufield.DestinationAddr, sm.Dst)
+	f.Set(pdufield.ESMClass, sm.ESMClass)
+	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
+	f.Set(pdufield.DataCoding, uint8(sm.Text.Type()))
+	p.TLVFields().Set(pdutlv.TagMessagePayload, sm.Text.Encode())
+	resp, err := t.do(ctx, p)
+	return sm.update(resp, err, pdu.DataSMRespID)
+}
+
+func setSubmitMultiFields(sm *ShortMessage, p pdu.Body, dataCoding uint8) error {
 	numberOfDest := len(sm.DstList) + len(sm.DLs) // TODO: Validate numbers and lists according to size
 	if numberOfDest > MaxDestinationAddress {
-		return nil, fmt.Errorf("Error: Max number of destination addresses allowed is %d, trying to send to %d",
+		return fmt.Errorf("Error: Max number of destination addresses allowed is %d, trying to send to %d",
 			MaxDestinationAddress, numberOfDest)
 	}
 	// Put destination addresses and lists inside an byte array
@@ -478,7 +1061,7 @@ func (t *Transmitter) submitMsgMulti(sm *ShortMessage, p pdu.Body, dataCoding ui
 	f := p.Fields()
 	f.Set(pdufield.SourceAddr, sm.Src)
 	f.Set(pdufield.DestinationList, bArray)
-	f.Set(pdufield.ShortMessage, sm.Text)
+	setShortMessage(f, sm)
 	f.Set(pdufield.NumberDests, uint8(numberOfDest))
 	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
 	// Check if the message has validity set.
@@ -488,30 +1071,15 @@ func (t *Transmitter) submitMsgMulti(sm *ShortMessage, p pdu.Body, dataCoding ui
 	f.Set(pdufield.ServiceType, sm.ServiceType)
 	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
 	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
-	f.Set(pdufield.ESMClass, sm.ESMClass)
+	f.Set(pdufield.ESMClass, esmClass(sm))
+	setPortTLVs(p, sm)
 	f.Set(pdufield.ProtocolID, sm.ProtocolID)
 	f.Set(pdufield.PriorityFlag, sm.PriorityFlag)
 	f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
 	f.Set(pdufield.ReplaceIfPresentFlag, sm.ReplaceIfPresentFlag)
 	f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
 	f.Set(pdufield.DataCoding, dataCoding)
-	resp, err := t.do(p)
-	if err != nil {
-		return nil, err
-	}
-	sm.resp.Lock()
-	sm.resp.p = resp.PDU
-	sm.resp.Unlock()
-	if resp.PDU == nil {
-		return nil, fmt.Errorf("unexpected empty PDU")
-	}
-	if id := resp.PDU.Header().ID; id != pdu.SubmitMultiRespID {
-		return sm, fmt.Errorf("unexpected PDU ID: %s", id)
-	}
-	if s := resp.PDU.Header().Status; s != 0 {
-		return sm, s
-	}
-	return sm, resp.Err
+	return nil
 }
 
 // QueryResp contains the parsed the response of a QuerySM request.
@@ -525,6 +1093,12 @@ type QueryResp struct {
 // QuerySM queries the delivery status of a message. It requires the
 // source address (sender) with TON and NPI and message ID.
 func (t *Transmitter) QuerySM(src, msgid string, srcTON, srcNPI uint8) (*QueryResp, error) {
+	return t.QuerySMContext(context.Background(), src, msgid, srcTON, srcNPI)
+}
+
+// QuerySMContext is like QuerySM but honours the deadline and
+// cancellation of ctx, returning ctx.Err() when ctx is done first.
+func (t *Transmitter) QuerySMContext(ctx context.Context, src, msgid string, srcTON, srcNPI uint8) (*QueryResp, error) {
 	p := pdu.NewQuerySM()
 	f := p.Fields()
 	f.Set(pdufield.SourceAddr, src)
@@ -532,7 +1106,7 @@ func (t *Transmitter) QuerySM(src, msgid string, srcTON, srcNPI uint8) (*QueryRe
 	f.Set(pdufield.SourceAddrNPI, srcNPI)
 	f.Set(pdufield.MessageID, msgid)
 
-	resp, err := t.do(p)
+	resp, err := t.do(ctx, p)
 	if err != nil {
 		return nil, err
 	}
@@ -548,30 +1122,7 @@ func (t *Transmitter) QuerySM(src, msgid string, srcTON, srcNPI uint8) (*QueryRe
 		return nil, fmt.Errorf("no state available")
 	}
 	qr := &QueryResp{MsgID: msgid}
-	switch ms.Bytes()[0] {
-	case 0:
-		qr.MsgState = "SCHEDULED"
-	case 1:
-		qr.MsgState = "ENROUTE"
-	case 2:
-		qr.MsgState = "DELIVERED"
-	case 3:
-		qr.MsgState = "EXPIRED"
-	case 4:
-		qr.MsgState = "DELETED"
-	case 5:
-		qr.MsgState = "UNDELIVERABLE"
-	case 6:
-		qr.MsgState = "ACCEPTED"
-	case 7:
-		qr.MsgState = "UNKNOWN"
-	case 8:
-		qr.MsgState = "REJECTED"
-	case 9:
-		qr.MsgState = "SKIPPED"
-	default:
-		qr.MsgState = fmt.Sprintf("UNKNOWN (%d)", ms.Bytes()[0])
-	}
+	qr.MsgState = messageState(ms.Bytes()[0])
 	if fd := f[pdufield.FinalDate]; fd != nil {
 		qr.FinalDate = fd.String()
 	}
@@ -581,8 +1132,112 @@ func (t *Transmitter) QuerySM(src, msgid string, srcTON, srcNPI uint8) (*QueryRe
 	return qr, nil
 }
 
+// CancelSM cancels a previously submitted message that is still
+// pending delivery. It uses the source and destination addresses of
+// the given sm, along with its ServiceType. If msgid is empty, the SMSC
+// cancels all pending messages from sm.Src to sm.Dst.
+func (t *Transmitter) CancelSM(msgid string, sm *ShortMessage) error {
+	return t.CancelSMContext(context.Background(), msgid, sm)
+}
+
+// CancelSMContext is like CancelSM but honours the deadline and
+// cancellation of ctx, returning ctx.Err() when ctx is done first.
+func (t *Transmitter) CancelSMContext(ctx context.Context, msgid string, sm *ShortMessage) error {
+	p := pdu.NewCancelSM()
+	f := p.Fields()
+	f.Set(pdufield.ServiceType, sm.ServiceType)
+	f.Set(pdufield.MessageID, msgid)
+	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, sm.Src)
+	f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
+	f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
+	f.Set(pdufield.DestinationAddr, sm.Dst)
+	resp, err := t.do(ctx, p)
+	if err != nil {
+		return err
+	}
+	if id := resp.PDU.Header().ID; id != pdu.CancelSMRespID {
+		return fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return s
+	}
+	return nil
+}
+
+// ReplaceSM replaces the text and delivery settings of a previously
+// submitted message that is still pending delivery. The source address,
+// Text, Validity, Register, ScheduleDeliveryTime and SMDefaultMsgID of
+// the given sm are used, and sm is updated with the response PDU.
+// It returns the same sm object.
+func (t *Transmitter) ReplaceSM(msgid string, sm *ShortMessage) (*ShortMessage, error) {
+	return t.ReplaceSMContext(context.Background(), msgid, sm)
+}
+
+// ReplaceSMContext is like ReplaceSM but honours the deadline and
+// cancellation of ctx, returning ctx.Err() when ctx is done first.
+func (t *Transmitter) ReplaceSMContext(ctx context.Context, msgid string, sm *ShortMessage) (*ShortMessage, error) {
+	p := pdu.NewReplaceSM()
+	f := p.Fields()
+	f.Set(pdufield.MessageID, msgid)
+	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, sm.Src)
+	f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
+	if sm.Validity != time.Duration(0) {
+		f.Set(pdufield.ValidityPeriod, convertValidity(sm.Validity))
+	}
+	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
+	f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
+	// replace_sm has no data_coding field: the text must be encoded
+	// with the same codec used for the original message.
+	f.Set(pdufield.ShortMessage, sm.Text.Encode())
+	resp, err := t.do(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	sm.resp.Lock()
+	sm.resp.p = resp.PDU
+	sm.resp.Unlock()
+	if id := resp.PDU.Header().ID; id != pdu.ReplaceSMRespID {
+		return sm, fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return sm, s
+	}
+	return sm, nil
+}
+
 func convertValidity(d time.Duration) string {
 	validity := time.Now().UTC().Add(d)
 	// Absolute time format YYMMDDhhmmsstnnp, see SMPP3.4 spec 7.1.1.
 	return validity.Format("060102150405") + "000+"
 }
+
+// messageState returns the name of the given message_state value.
+func messageState(s uint8) string {
+	switch s {
+	case 0:
+		return "SCHEDULED"
+	case 1:
+		return "ENROUTE"
+	case 2:
+		return "DELIVERED"
+	case 3:
+		return "EXPIRED"
+	case 4:
+		return "DELETED"
+	case 5:
+		return "UNDELIVERABLE"
+	case 6:
+		return "ACCEPTED"
+	case 7:
+		return "UNKNOWN"
+	case 8:
+		return "REJECTED"
+	case 9:
+		return "SKIPPED"
+	}
+	return fmt.Sprintf("UNKNOWN (%d)", s)
+}
@@ -5,16 +5,23 @@
 package smpp
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"golang.org/x/time/rate"
 
-	"github.com/fiorix/go-smpp/smpp/pdu"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
-	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
-	"github.com/fiorix/go-smpp/smpp/smpptest"
+	"github.com/fiorix/go-smpp/v2/smpp/encoding"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/v2/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/v2/smpp/smpptest"
+	"github.com/fiorix/go-smpp/v2/smpp/wappush"
 )
 
 func TestShortMessage(t *testing.T) {
@@ -120,6 +127,62 @@ func TestShortMessageWindowSize(t *testing.T) {
 	}
 }
 
+func TestShortMessageWindowWait(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		time.Sleep(200 * time.Millisecond)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		r.Fields().Set(pdufield.MessageID, "foobar")
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		WindowSize:  2,
+		WindowWait:  true,
+		RespTimeout: time.Second,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	msgc := make(chan *ShortMessage, 3)
+	defer close(msgc)
+	errc := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func(msgc chan *ShortMessage, errc chan error) {
+			m := <-msgc
+			if m == nil {
+				return
+			}
+			_, err := tx.Submit(m)
+			errc <- err
+		}(msgc, errc)
+		msgc <- &ShortMessage{
+			Src:      "root",
+			Dst:      "foobar",
+			Text:     pdutext.Raw("Lorem ipsum"),
+			Validity: 10 * time.Minute,
+			Register: pdufield.NoDeliveryReceipt,
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ws := tx.WindowStatus(); ws.Inflight != 0 || ws.Queued != 0 {
+		t.Fatalf("unexpected window status: %#v", ws)
+	}
+}
+
 func TestLongMessage(t *testing.T) {
 	s := smpptest.NewUnstartedServer()
 	count := 0
@@ -162,7 +225,8 @@ func TestLongMessage(t *testing.T) {
 	if len(parts) != 2 {
 		t.Fatalf("expected %d responses, but received %d", 2, len(parts))
 	}
-	for index, sm := range parts {
+	for index := range parts {
+		sm := &parts[index]
 		msgid := sm.RespID()
 		if msgid == "" {
 			t.Fatalf("pdu does not contain msgid: %#v", sm.Resp())
@@ -173,6 +237,78 @@ func TestLongMessage(t *testing.T) {
 	}
 }
 
+func TestLongMessageGSM7Packed(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	var received []pdu.Body
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		received = append(received, p)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		r.Fields().Set(pdufield.MessageID, "foobar")
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	// 151 septets before the euro sign, escaped in two septets, that
+	// must not be split between the parts.
+	text := strings.Repeat("Lorem ipsum dolor sit amet. ", 5) + strings.Repeat("x", 11) + "€" +
+		strings.Repeat(" Nam consequat nisl enim.", 3)
+	parts, err := tx.SubmitLongMsg(&ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.GSM7Packed(text),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 || len(received) != 2 {
+		t.Fatalf("unexpected number of parts: %d sent, %d received", len(parts), len(received))
+	}
+	var have string
+	for i, p := range received {
+		if esm := p.Fields()[pdufield.ESMClass].Bytes()[0]; esm&0x40 == 0 {
+			t.Fatalf("part %d: UDHI not set", i)
+		}
+		sm := p.Fields()[pdufield.ShortMessage].Bytes()
+		if len(sm) > 140 {
+			t.Fatalf("part %d: short message too long: %d", i, len(sm))
+		}
+		// Drop the septets the header and its fill bits take.
+		septets := unpackSeptets(sm)[(int(sm[0]+1)*8+6)/7:]
+		have += strings.TrimRight(string(pdutext.GSM7(septets).Decode()), "@")
+	}
+	if have != text {
+		t.Fatalf("unexpected text:\nwant %q\nhave %q", text, have)
+	}
+}
+
+// unpackSeptets returns the septets packed in b, padding included.
+func unpackSeptets(b []byte) []byte {
+	s := make([]byte, len(b)*8/7)
+	for i := range s {
+		bit := i * 7
+		v := uint16(b[bit/8])
+		if bit/8+1 < len(b) {
+			v |= uint16(b[bit/8+1]) << 8
+		}
+		s[i] = byte(v>>uint(bit%8)) & 0x7F
+	}
+	return s
+}
+
 func TestLongMessageAsUCS2(t *testing.T) {
 	s := smpptest.NewUnstartedServer()
 	var receivedMsg string
@@ -226,7 +362,8 @@ func TestLongMessageAsUCS2(t *testing.T) {
 	if len(parts) != 3 {
 		t.Fatalf("expected %d responses, but received %d", 3, len(parts))
 	}
-	for index, sm := range parts {
+	for index := range parts {
+		sm := &parts[index]
 		msgid := sm.RespID()
 		if msgid == "" {
 			t.Fatalf("pdu does not contain msgid: %#v", sm.Resp())
@@ -385,3 +522,857 @@ func TestNotConnected(t *testing.T) {
 	}
 
 }
+
+func TestCancelSM(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.CancelSMID:
+			f := p.Fields()
+			r := pdu.NewCancelSMRespSeq(p.Header().Seq)
+			if f[pdufield.MessageID].String() != "13" ||
+				f[pdufield.DestinationAddr].String() != "foobar" {
+				r.Header().Status = 0x11 // cancelsm failed
+			}
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{Src: "root", Dst: "foobar"}
+	if err := tx.CancelSM("13", sm); err != nil {
+		t.Fatal(err)
+	}
+	err := tx.CancelSM("14", sm)
+	if err != pdu.Status(0x11) {
+		t.Fatalf("unexpected error: want %v, have %v", pdu.Status(0x11), err)
+	}
+}
+
+func TestReplaceSM(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.ReplaceSMID:
+			f := p.Fields()
+			r := pdu.NewReplaceSMRespSeq(p.Header().Seq)
+			if f[pdufield.MessageID].String() != "13" ||
+				f[pdufield.ShortMessage].String() != "Lorem ipsum" {
+				r.Header().Status = 0x13 // replacesm failed
+			}
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm, err := tx.ReplaceSM("13", &ShortMessage{
+		Src:      "root",
+		Text:     pdutext.Raw("Lorem ipsum"),
+		Validity: 10 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := sm.Resp().Header().ID; id != pdu.ReplaceSMRespID {
+		t.Fatalf("unexpected response: want %s, have %s", pdu.ReplaceSMRespID, id)
+	}
+}
+
+func TestSubmitData(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.DataSMID:
+			r := pdu.NewDataSMRespSeq(p.Header().Seq)
+			payload := p.TLVFields()[pdutlv.TagMessagePayload]
+			if payload == nil || string(payload.Bytes()) != "Lorem ipsum" {
+				r.Header().Status = 0xfe // delivery failure
+			}
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm, err := tx.SubmitData(&ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgid := sm.RespID(); msgid != "foobar" {
+		t.Fatalf("unexpected msgid: want foobar, have %q", msgid)
+	}
+}
+
+func TestSubmitContext(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		// Never respond to submit_sm.
+		if p.Header().ID != pdu.SubmitSMID {
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		RespTimeout: 10 * time.Second,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := tx.SubmitContext(ctx, sm)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: want %v, have %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("SubmitContext took too long: %s", d)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = tx.QuerySMContext(ctx, "root", "13", 0, 0)
+	if err != context.Canceled {
+		t.Fatalf("unexpected error: want %v, have %v", context.Canceled, err)
+	}
+	if _, err = tx.SubmitDataContext(ctx, sm); err != context.Canceled {
+		t.Fatalf("unexpected data_sm error: want %v, have %v", context.Canceled, err)
+	}
+	if err = tx.CancelSMContext(ctx, "13", sm); err != context.Canceled {
+		t.Fatalf("unexpected cancel_sm error: want %v, have %v", context.Canceled, err)
+	}
+	if _, err = tx.ReplaceSMContext(ctx, "13", sm); err != context.Canceled {
+		t.Fatalf("unexpected replace_sm error: want %v, have %v", context.Canceled, err)
+	}
+}
+
+func TestSubmitAsync(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, fmt.Sprintf("foobar%d", p.Header().Seq))
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		WindowSize:  2,
+		WindowWait:  true,
+		RespTimeout: time.Second,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	const n = 10
+	errc := make(chan error, n)
+	for i := 0; i < n; i++ {
+		sm := &ShortMessage{
+			Src:  "root",
+			Dst:  "foobar",
+			Text: pdutext.Raw("Lorem ipsum"),
+		}
+		err := tx.SubmitAsync(context.Background(), sm, func(resp *ShortMessage, err error) {
+			if err == nil && resp.RespID() == "" {
+				err = fmt.Errorf("pdu does not contain msgid: %#v", resp.Resp())
+			}
+			errc <- err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ws := tx.WindowStatus(); ws.Inflight != 0 || ws.Queued != 0 {
+		t.Fatalf("unexpected window status: %#v", ws)
+	}
+	tx.Close()
+	sm := &ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")}
+	err := tx.SubmitAsync(context.Background(), sm, func(*ShortMessage, error) {
+		t.Fatal("callback called for unsent message")
+	})
+	if err != ErrNotConnected {
+		t.Fatalf("unexpected error: want %v, have %v", ErrNotConnected, err)
+	}
+}
+
+func TestSubmitAsyncCancel(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		r.Fields().Set(pdufield.MessageID, "foobar")
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		RespTimeout: time.Second,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	// Cancelling ctx after the message is sent does not fail it.
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	sm := &ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")}
+	err := tx.SubmitAsync(ctx, sm, func(resp *ShortMessage, err error) {
+		if err == nil && resp.RespID() != "foobar" {
+			err = fmt.Errorf("unexpected msgid: %q", resp.RespID())
+		}
+		errc <- err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err = <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitText(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, fmt.Sprintf("foobar%d", p.Header().Seq))
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	test := []struct {
+		text   string
+		coding pdutext.DataCoding
+		parts  int
+	}{
+		{"Lorem ipsum", pdutext.DefaultType, 1},
+		{strings.Repeat("Lorem ipsum {} ", 11), pdutext.DefaultType, 2},
+		{"Olá mundão ✓", pdutext.UCS2Type, 1},
+		{strings.Repeat("✓", 140), pdutext.UCS2Type, 3},
+	}
+	for _, tc := range test {
+		parts, err := tx.SubmitText(&ShortMessage{Src: "root", Dst: "foobar"}, tc.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != tc.parts {
+			t.Fatalf("%q: unexpected number of parts: want %d, have %d", tc.text, tc.parts, len(parts))
+		}
+		var text []byte
+		for index := range parts {
+			if parts[index].RespID() == "" {
+				t.Fatalf("pdu does not contain msgid: %#v", parts[index].Resp())
+			}
+			p := <-rc
+			f := p.Fields()
+			if c := pdutext.DataCoding(f[pdufield.DataCoding].Bytes()[0]); c != tc.coding {
+				t.Fatalf("%q: unexpected data coding: want %#x, have %#x", tc.text, tc.coding, c)
+			}
+			b := f[pdufield.ShortMessage].Bytes()
+			if tc.parts > 1 {
+				b = b[7:]
+			}
+			text = append(text, b...)
+		}
+		var have []byte
+		if tc.coding == pdutext.UCS2Type {
+			have = pdutext.UCS2(text).Decode()
+		} else {
+			have = pdutext.GSM7(text).Decode()
+		}
+		if string(have) != tc.text {
+			t.Fatalf("unexpected text: want %q, have %q", tc.text, have)
+		}
+	}
+}
+
+func TestSubmitTextNational(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	test := []struct {
+		text  string
+		parts int
+	}{
+		{strings.Repeat("ı", 100), 1},
+		{strings.Repeat("ı", 153), 2},
+	}
+	for _, tc := range test {
+		parts, err := tx.SubmitText(&ShortMessage{Src: "root", Dst: "foobar"}, tc.text, encoding.Turkish)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != tc.parts {
+			t.Fatalf("unexpected number of parts: want %d, have %d", tc.parts, len(parts))
+		}
+		var text []byte
+		for range parts {
+			p := <-rc
+			f := p.Fields()
+			if esm := f[pdufield.ESMClass].Bytes()[0]; esm&0x40 == 0 {
+				t.Fatalf("unexpected esm_class: %#x", esm)
+			}
+			b := f[pdufield.ShortMessage].Bytes()
+			udh := b[1 : b[0]+1]
+			s := pdutext.NewGSM7National(udh, b[b[0]+1:])
+			if s.Locking != encoding.Turkish || s.Single != encoding.Turkish {
+				t.Fatalf("unexpected shift tables in header: %x", udh)
+			}
+			text = append(text, s.Decode()...)
+		}
+		if string(text) != tc.text {
+			t.Fatalf("unexpected text: want %q, have %q", tc.text, text)
+		}
+	}
+}
+
+func TestSubmitUDH(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	port := pdufield.NewPort16IE(5499, 0)
+	sm := &ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Binary2("hello"),
+		UDH:  pdufield.NewUDHList(port),
+	}
+	if _, err := tx.Submit(sm); err != nil {
+		t.Fatal(err)
+	}
+	p := <-rc
+	f := p.Fields()
+	if esm := f[pdufield.ESMClass].Bytes()[0]; esm != 0x40 {
+		t.Fatalf("unexpected esm_class: %#x", esm)
+	}
+	want := append(sm.UDH.Header(), "hello"...)
+	if have := f[pdufield.ShortMessage].Bytes(); !bytes.Equal(have, want) {
+		t.Fatalf("unexpected short message: want %x, have %x", want, have)
+	}
+	sm.Text = pdutext.Binary2(bytes.Repeat([]byte("x"), 200))
+	parts, err := tx.SubmitLongMsg(sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("unexpected number of parts: %d", len(parts))
+	}
+	var text []byte
+	for i := range parts {
+		b := (<-rc).Fields()[pdufield.ShortMessage].Bytes()
+		if len(b) > 140 {
+			t.Fatalf("part %d is too long: %d octets", i, len(b))
+		}
+		udh, data, err := pdufield.ParseUDH(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, total, seq, ok := udh.Concat(); !ok || total != 2 || seq != i+1 {
+			t.Fatalf("unexpected concatenation in part %d: %x", i, udh.Header())
+		}
+		if ie := udh.Find(pdufield.IEIPort16); ie == nil || !bytes.Equal(ie.IEData.Data, port.IEData.Data) {
+			t.Fatalf("missing port addressing in part %d: %x", i, udh.Header())
+		}
+		text = append(text, data...)
+	}
+	if !bytes.Equal(text, sm.Text.Encode()) {
+		t.Fatalf("unexpected text: %q", text)
+	}
+}
+
+func TestSubmitPorts(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	// Ports in TLVs.
+	sm := &ShortMessage{
+		Src:      "root",
+		Dst:      "foobar",
+		Text:     pdutext.Binary2("BEGIN:VCARD"),
+		SrcPort:  wappush.VCardPort,
+		DstPort:  wappush.VCardPort,
+		PortTLVs: true,
+	}
+	if _, err := tx.Submit(sm); err != nil {
+		t.Fatal(err)
+	}
+	p := <-rc
+	if esm := p.Fields()[pdufield.ESMClass].Bytes()[0]; esm != 0 {
+		t.Fatalf("unexpected esm_class: %#x", esm)
+	}
+	for _, tag := range []pdutlv.Tag{pdutlv.TagSourcePort, pdutlv.TagDestinationPort} {
+		if v := p.TLVFields()[tag]; v == nil || !bytes.Equal(v.Bytes(), []byte{0x23, 0xf4}) {
+			t.Fatalf("unexpected TLV %#x: %v", tag, v)
+		}
+	}
+	// Ports in the user data header, with segmentation.
+	sm = &ShortMessage{Src: "root", Dst: "foobar", SrcPort: 1000, DstPort: 2000}
+	text := strings.Repeat("x", 200)
+	parts, err := tx.SubmitText(sm, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("unexpected number of parts: %d", len(parts))
+	}
+	var have []byte
+	for i := range parts {
+		b := (<-rc).Fields()[pdufield.ShortMessage].Bytes()
+		udh, data, err := pdufield.ParseUDH(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := (len(udh.Header())*8+6)/7 + len(data); n > 160 {
+			t.Fatalf("part %d is too long: %d septets", i, n)
+		}
+		ie := udh.Find(pdufield.IEIPort16)
+		if ie == nil || !bytes.Equal(ie.IEData.Data, []byte{0x07, 0xd0, 0x03, 0xe8}) {
+			t.Fatalf("unexpected port addressing in part %d: %x", i, udh.Header())
+		}
+		have = append(have, pdutext.GSM7(data).Decode()...)
+	}
+	if string(have) != text {
+		t.Fatalf("unexpected text: %q", have)
+	}
+	// WAP Push.
+	si := &wappush.SI{Href: "https://www.example.com/", Text: "hello"}
+	push := wappush.Push(1, wappush.ContentTypeSI, si.WBXML())
+	for _, n := range []int{1, 3} {
+		parts, err := tx.SubmitWAPPush(&ShortMessage{Src: "root", Dst: "foobar"}, bytes.Repeat(push, n*n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != n {
+			t.Fatalf("unexpected number of parts: want %d, have %d", n, len(parts))
+		}
+		for range parts {
+			f := (<-rc).Fields()
+			if dc := f[pdufield.DataCoding].Bytes()[0]; dc != 0x04 {
+				t.Fatalf("unexpected data_coding: %#x", dc)
+			}
+			udh, _, err := pdufield.ParseUDH(f[pdufield.ShortMessage].Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ie := udh.Find(pdufield.IEIPort16); ie == nil || !bytes.Equal(ie.IEData.Data, []byte{0x0b, 0x84, 0x23, 0xf0}) {
+				t.Fatalf("unexpected port addressing: %x", udh.Header())
+			}
+		}
+	}
+}
+
+func TestConnStatusHandler(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+			c.Write(pdu.NewUnbind())
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	events := make(chan ConnStatus, 10)
+	tx := &Transmitter{
+		Addr:              s.Addr(),
+		User:              smpptest.DefaultUser,
+		Passwd:            smpptest.DefaultPasswd,
+		BindInterval:      10 * time.Millisecond,
+		ConnStatusHandler: func(s ConnStatus) { events <- s },
+	}
+	if st := tx.State(); st != 0 {
+		t.Fatalf("unexpected state before bind: %s", st)
+	}
+	status := tx.Bind()
+	defer tx.Close()
+	next := func() ConnStatus {
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for status change")
+			return nil
+		}
+	}
+	if ev := next(); ev.Status() != Connected {
+		t.Fatalf("unexpected status: %s", ev.Status())
+	}
+	_, err := tx.Submit(&ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := next(); ev.Status() != Unbound {
+		t.Fatalf("unexpected status: %s", ev.Status())
+	}
+	ev := next()
+	rs, ok := ev.(*ReconnectStatus)
+	if !ok {
+		t.Fatalf("unexpected status: %s", ev.Status())
+	}
+	if rs.Attempt != 1 || rs.Delay != tx.BindInterval {
+		t.Fatalf("unexpected reconnect status: %+v", rs)
+	}
+	if ev := next(); ev.Status() != Connected {
+		t.Fatalf("unexpected status: %s", ev.Status())
+	}
+	if st := tx.State(); st != Connected {
+		t.Fatalf("unexpected state: %s", st)
+	}
+	// The channel was not drained and holds the latest status.
+	select {
+	case ev := <-status:
+		if ev.Status() != Connected {
+			t.Fatalf("unexpected status on channel: %s", ev.Status())
+		}
+	default:
+		t.Fatal("no status on channel")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		if p.Fields()[pdufield.DestinationAddr].String() == "never" {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		r.Fields().Set(pdufield.MessageID, "foobar")
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	bind := func() *Transmitter {
+		tx := &Transmitter{
+			Addr:        s.Addr(),
+			User:        smpptest.DefaultUser,
+			Passwd:      smpptest.DefaultPasswd,
+			RespTimeout: 5 * time.Second,
+		}
+		conn := <-tx.Bind()
+		if conn.Status() != Connected {
+			t.Fatal(conn.Error())
+		}
+		return tx
+	}
+	submit := func(tx *Transmitter, dst string, done chan error) {
+		sm := &ShortMessage{Src: "root", Dst: dst, Text: pdutext.Raw("Lorem ipsum")}
+		err := tx.SubmitAsync(context.Background(), sm, func(sm *ShortMessage, err error) {
+			done <- err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Requests in flight are answered before unbinding.
+	tx := bind()
+	defer tx.Close()
+	done := make(chan error, 1)
+	submit(tx, "foobar", done)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tx.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("request in flight failed: %v", err)
+	}
+	_, err := tx.Submit(&ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")})
+	if err != ErrShutdown {
+		t.Fatalf("unexpected error after shutdown: %v", err)
+	}
+
+	// Requests without response are reported.
+	tx = bind()
+	defer tx.Close()
+	submit(tx, "never", done)
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = tx.Shutdown(ctx)
+	var se *ShutdownError
+	if !errors.As(err, &se) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected cause: %v", se.Err)
+	}
+	if len(se.Unacked) != 1 || se.Unacked[0].Header().ID != pdu.SubmitSMID {
+		t.Fatalf("unexpected unacknowledged requests: %v", se.Unacked)
+	}
+	if err := <-done; err != ErrNotConnected {
+		t.Fatalf("unexpected error of unacknowledged request: %v", err)
+	}
+}
+
+func TestGenericNack(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		if p.Header().ID != pdu.SubmitSMID {
+			return
+		}
+		r := pdu.NewGenericNACK()
+		r.Header().Seq = p.Header().Seq
+		r.Header().Status = pdu.StatusSysErr
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != Connected {
+		t.Fatal(conn.Error())
+	}
+	_, err := tx.Submit(&ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")})
+	var nack *GenericNackError
+	if !errors.As(err, &nack) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nack.Status != pdu.StatusSysErr || !errors.Is(err, pdu.StatusSysErr) {
+		t.Fatalf("unexpected status: %v", nack.Status)
+	}
+	if ws := tx.WindowStatus(); ws.Inflight != 0 {
+		t.Fatalf("request left in flight: %+v", ws)
+	}
+}
+
+func TestPeerUnbind(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	unbindResp := make(chan pdu.Body, 1)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			c.Write(pdu.NewUnbind())
+		case pdu.UnbindRespID:
+			unbindResp <- p
+		}
+	}
+	s.Start()
+	defer s.Close()
+	connected := make(chan struct{}, 2)
+	tx := &Transmitter{
+		Addr:         s.Addr(),
+		User:         smpptest.DefaultUser,
+		Passwd:       smpptest.DefaultPasswd,
+		BindInterval: 10 * time.Millisecond,
+		RespTimeout:  5 * time.Second,
+		ConnStatusHandler: func(s ConnStatus) {
+			if s.Status() == Connected {
+				connected <- struct{}{}
+			}
+		},
+	}
+	defer tx.Close()
+	tx.Bind()
+	<-connected
+	_, err := tx.Submit(&ShortMessage{Src: "root", Dst: "foobar", Text: pdutext.Raw("Lorem ipsum")})
+	if err != ErrNotConnected {
+		t.Fatalf("unexpected error of request in flight: %v", err)
+	}
+	select {
+	case <-unbindResp:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for UnbindResp")
+	}
+	select {
+	case <-connected:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reconnection")
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package wappush provides WAP Push messages for binary SMS.
+//
+// Service Indication (SI) and Service Loading (SL) documents are encoded
+// in WBXML, as in WAP-167 and WAP-168, and wrapped in a connectionless
+// WSP push PDU that is sent as 8-bit data to port 2948 of the handset.
+//
+// Example:
+//
+//	si := &wappush.SI{Href: "https://www.example.com/", Text: "Hello"}
+//	sm := &smpp.ShortMessage{Src: "root", Dst: "foobar"}
+//	parts, err := tx.SubmitWAPPush(sm, wappush.Push(1, wappush.ContentTypeSI, si.WBXML()))
+package wappush
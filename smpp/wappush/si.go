@@ -0,0 +1,81 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package wappush
+
+import (
+	"time"
+)
+
+// Service Indication tokens, from WAP-167.
+const (
+	siPublicID   = 0x05 // "-//WAPFORUM//DTD SI 1.0//EN"
+	siTag        = 0x05
+	siIndication = 0x06
+	siCreated    = 0x0A
+	siExpires    = 0x10
+	siID         = 0x11
+)
+
+var siActions = []token{
+	{"signal-none", 0x05},
+	{"signal-low", 0x06},
+	{"signal-medium", 0x07},
+	{"signal-high", 0x08},
+	{"delete", 0x09},
+}
+
+var siHrefPrefixes = []token{
+	{"https://www.", 0x0F},
+	{"https://", 0x0E},
+	{"http://www.", 0x0D},
+	{"http://", 0x0C},
+	{"", 0x0B},
+}
+
+var hrefValues = []token{
+	{".com/", 0x85},
+	{".edu/", 0x86},
+	{".net/", 0x87},
+	{".org/", 0x88},
+}
+
+// SI is a Service Indication, a notification with a link to a service
+// that the user may load.
+type SI struct {
+	Href    string    // URL of the service.
+	ID      string    // Identifies the indication, e.g. to replace it; optional.
+	Created time.Time // Time the content was created; optional.
+	Expires time.Time // Time the indication expires; optional.
+	Action  string    // signal-none, signal-low, signal-medium, signal-high or delete; optional.
+	Text    string    // Message shown to the user.
+}
+
+// WBXML returns the SI document encoded in WBXML. An invalid Action is
+// replaced by the default, signal-medium.
+func (si *SI) WBXML() []byte {
+	b := wbxmlHeader(siPublicID)
+	b = append(b, siTag|wbxmlContent, siIndication|wbxmlContent|wbxmlAttributes)
+	b = append(b, wbxmlHref(si.Href, siHrefPrefixes, hrefValues)...)
+	if si.ID != "" {
+		b = append(b, siID)
+		b = append(b, wbxmlString(si.ID)...)
+	}
+	if !si.Created.IsZero() {
+		b = append(b, siCreated)
+		b = append(b, wbxmlDate(si.Created)...)
+	}
+	if !si.Expires.IsZero() {
+		b = append(b, siExpires)
+		b = append(b, wbxmlDate(si.Expires)...)
+	}
+	if si.Action != "" {
+		b = append(b, attrToken(si.Action, siActions, 0x07)) // signal-medium
+	}
+	b = append(b, wbxmlEnd) // attributes of indication
+	if si.Text != "" {
+		b = append(b, wbxmlString(si.Text)...)
+	}
+	return append(b, wbxmlEnd, wbxmlEnd) // indication, si
+}
@@ -0,0 +1,44 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package wappush
+
+// Service Loading tokens, from WAP-168.
+const (
+	slPublicID = 0x06 // "-//WAPFORUM//DTD SL 1.0//EN"
+	slTag      = 0x05
+)
+
+var slActions = []token{
+	{"execute-low", 0x05},
+	{"execute-high", 0x06},
+	{"cache", 0x07},
+}
+
+var slHrefPrefixes = []token{
+	{"https://www.", 0x0C},
+	{"https://", 0x0B},
+	{"http://www.", 0x0A},
+	{"http://", 0x09},
+	{"", 0x08},
+}
+
+// SL is a Service Loading, which makes the handset load a service,
+// without user intervention if the handset allows it.
+type SL struct {
+	Href   string // URL of the service.
+	Action string // execute-low, execute-high or cache; optional.
+}
+
+// WBXML returns the SL document encoded in WBXML. An invalid Action is
+// replaced by the default, execute-low.
+func (sl *SL) WBXML() []byte {
+	b := wbxmlHeader(slPublicID)
+	b = append(b, slTag|wbxmlAttributes)
+	b = append(b, wbxmlHref(sl.Href, slHrefPrefixes, hrefValues)...)
+	if sl.Action != "" {
+		b = append(b, attrToken(sl.Action, slActions, 0x05)) // execute-low
+	}
+	return append(b, wbxmlEnd) // attributes of sl
+}
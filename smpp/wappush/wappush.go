@@ -0,0 +1,46 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package wappush
+
+// Application ports of WAP Push and other well-known binary messages.
+const (
+	Port          = 2948 // Connectionless WSP push.
+	OriginPort    = 9200 // Connectionless WSP, source of push messages.
+	VCardPort     = 9204 // vCard.
+	VCalendarPort = 9205 // vCalendar.
+)
+
+// Well-known WSP content types of push messages.
+const (
+	ContentTypeSI           = 0x2E // application/vnd.wap.sic
+	ContentTypeSL           = 0x30 // application/vnd.wap.slc
+	ContentTypeProvisioning = 0x36 // application/vnd.wap.connectivity-wbxml
+)
+
+// WSP PDU type of push messages.
+const pduTypePush = 0x06
+
+// Push returns the connectionless WSP push PDU with transaction ID tid
+// of body, a WBXML document of the given well-known content type. The
+// content type has the UTF-8 charset parameter.
+func Push(tid, contentType uint8, body []byte) []byte {
+	// Content-type in general form: value length, well-known media
+	// and the charset parameter (0x81) with UTF-8 (0xEA).
+	headers := []byte{0x03, contentType | 0x80, 0x81, 0xEA}
+	b := []byte{tid, pduTypePush}
+	b = append(b, uintvar(len(headers))...)
+	b = append(b, headers...)
+	return append(b, body...)
+}
+
+// uintvar returns the WSP variable length encoding of n, 7 bits per
+// octet with the continuation bit set in all but the last one.
+func uintvar(n int) []byte {
+	b := []byte{uint8(n & 0x7f)}
+	for n >>= 7; n > 0; n >>= 7 {
+		b = append([]byte{uint8(n&0x7f) | 0x80}, b...)
+	}
+	return b
+}
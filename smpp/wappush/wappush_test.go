@@ -0,0 +1,61 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package wappush
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSI(t *testing.T) {
+	// Example from WAP-167 section 8.3.
+	si := &SI{
+		Href:    "http://www.xyz.com/email/123/abc.wml",
+		Created: time.Date(1999, 6, 25, 15, 23, 15, 0, time.UTC),
+		Expires: time.Date(1999, 6, 30, 0, 0, 0, 0, time.UTC),
+		Text:    "You have 4 new emails",
+	}
+	want := []byte("\x02\x05\x6a\x00\x45\xc6\x0d\x03xyz\x00\x85\x03email/123/abc.wml\x00" +
+		"\x0a\xc3\x07\x19\x99\x06\x25\x15\x23\x15\x10\xc3\x04\x19\x99\x06\x30\x01" +
+		"\x03You have 4 new emails\x00\x01\x01")
+	if have := si.WBXML(); !bytes.Equal(have, want) {
+		t.Fatalf("unexpected SI:\nwant: %x\nhave: %x", want, have)
+	}
+	si = &SI{Href: "example.net/", ID: "1", Action: "delete"}
+	want = []byte("\x02\x05\x6a\x00\x45\xc6\x0b\x03example\x00\x87\x11\x031\x00\x09\x01\x01\x01")
+	if have := si.WBXML(); !bytes.Equal(have, want) {
+		t.Fatalf("unexpected SI:\nwant: %x\nhave: %x", want, have)
+	}
+}
+
+func TestSL(t *testing.T) {
+	test := []struct {
+		sl   *SL
+		want string
+	}{
+		{&SL{Href: "http://www.xyz.com/ppaid/123/abc.wml"},
+			"\x02\x06\x6a\x00\x85\x0a\x03xyz\x00\x85\x03ppaid/123/abc.wml\x00\x01"},
+		{&SL{Href: "https://example.org/", Action: "execute-high"},
+			"\x02\x06\x6a\x00\x85\x0b\x03example\x00\x88\x06\x01"},
+		{&SL{Href: "ftp://x", Action: "bogus"},
+			"\x02\x06\x6a\x00\x85\x08\x03ftp://x\x00\x05\x01"},
+	}
+	for _, tc := range test {
+		if have := tc.sl.WBXML(); !bytes.Equal(have, []byte(tc.want)) {
+			t.Fatalf("unexpected SL for %q:\nwant: %x\nhave: %x", tc.sl.Href, tc.want, have)
+		}
+	}
+}
+
+func TestPush(t *testing.T) {
+	want := []byte{0x01, 0x06, 0x04, 0x03, 0xae, 0x81, 0xea, 0x02, 0x05}
+	if have := Push(1, ContentTypeSI, []byte{0x02, 0x05}); !bytes.Equal(have, want) {
+		t.Fatalf("unexpected push PDU: want %x, have %x", want, have)
+	}
+	if have := uintvar(0x87a5); !bytes.Equal(have, []byte{0x82, 0x8f, 0x25}) {
+		t.Fatalf("unexpected uintvar: %x", have)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package wappush
+
+import (
+	"strings"
+	"time"
+)
+
+// WBXML global tokens.
+const (
+	wbxmlEnd    = 0x01
+	wbxmlStrI   = 0x03
+	wbxmlOpaque = 0xC3
+)
+
+// WBXML tag flags.
+const (
+	wbxmlContent    = 0x40
+	wbxmlAttributes = 0x80
+)
+
+// WBXML header, version 1.2 and the UTF-8 charset, with an empty
+// string table.
+func wbxmlHeader(publicID uint8) []byte {
+	return []byte{0x02, publicID, 0x6A, 0x00}
+}
+
+// token is a string with its WBXML token.
+type token struct {
+	s string
+	t uint8
+}
+
+// wbxmlString returns the inline string of s.
+func wbxmlString(s string) []byte {
+	b := append([]byte{wbxmlStrI}, s...)
+	return append(b, 0x00)
+}
+
+// wbxmlHref returns the attribute start token of href followed by its
+// value, using the longest of the prefixes that matches and the value
+// tokens found in the rest of href.
+func wbxmlHref(href string, prefixes, values []token) []byte {
+	var b []byte
+	for _, p := range prefixes {
+		if strings.HasPrefix(href, p.s) {
+			b = append(b, p.t)
+			href = href[len(p.s):]
+			break
+		}
+	}
+	for href != "" {
+		i, v := len(href), token{}
+		for _, t := range values {
+			if j := strings.Index(href, t.s); j >= 0 && j < i {
+				i, v = j, t
+			}
+		}
+		if i > 0 {
+			b = append(b, wbxmlString(href[:i])...)
+		}
+		if v.s == "" {
+			break
+		}
+		b = append(b, v.t)
+		href = href[i+len(v.s):]
+	}
+	return b
+}
+
+// wbxmlDate returns the opaque data of t, the digits of its UTC date
+// and time in BCD without trailing zero octets.
+func wbxmlDate(t time.Time) []byte {
+	s := t.UTC().Format("20060102150405")
+	d := make([]byte, len(s)/2)
+	for i := range d {
+		d[i] = (s[2*i]-'0')<<4 | (s[2*i+1] - '0')
+	}
+	for len(d) > 0 && d[len(d)-1] == 0 {
+		d = d[:len(d)-1]
+	}
+	return append([]byte{wbxmlOpaque, uint8(len(d))}, d...)
+}
+
+// attrToken returns the token of the attribute value v, or def if it
+// is not one of the given values.
+func attrToken(v string, values []token, def uint8) uint8 {
+	for _, t := range values {
+		if t.s == v {
+			return t.t
+		}
+	}
+	return def
+}
@@ -0,0 +1,122 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// WindowStatus reports the occupancy of the window of a Transmitter
+// or Transceiver.
+type WindowStatus struct {
+	Size     int // Configured WindowSize, zero if unlimited.
+	Inflight int // Requests waiting for a response.
+	Queued   int // Callers waiting for a free slot, if WindowWait is set.
+}
+
+// window limits the number of requests waiting for a response.
+//
+// When wait is set, callers that exceed the window are queued in
+// FIFO order until a slot is released, otherwise they are rejected
+// with ErrMaxWindowSize.
+type window struct {
+	size uint
+	wait bool
+
+	mu       sync.Mutex
+	limit    uint // effective size, reduced by flow control.
+	inflight uint
+	queue    list.List // of chan struct{}
+}
+
+// newWindow returns a window of the given size, unlimited if 0.
+func newWindow(size uint, wait bool) *window {
+	return &window{size: size, wait: wait, limit: size}
+}
+
+// acquire takes a slot from the window, waiting for one to be
+// released if the window is configured to wait. It returns ctx.Err()
+// if ctx is done while waiting.
+func (w *window) acquire(ctx context.Context) error {
+	w.mu.Lock()
+	if w.size == 0 || (w.inflight < w.limit && w.queue.Len() == 0) {
+		w.inflight++
+		w.mu.Unlock()
+		return nil
+	}
+	if !w.wait {
+		w.mu.Unlock()
+		return ErrMaxWindowSize
+	}
+	ready := make(chan struct{})
+	e := w.queue.PushBack(ready)
+	w.mu.Unlock()
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		w.mu.Lock()
+		select {
+		case <-ready:
+			// The slot was handed over to us while giving up.
+			w.mu.Unlock()
+			w.release()
+		default:
+			w.queue.Remove(e)
+			w.mu.Unlock()
+		}
+		return ctx.Err()
+	}
+}
+
+// release returns a slot to the window, handing it over to the
+// first queued caller if any.
+func (w *window) release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if e := w.queue.Front(); e != nil && w.inflight <= w.limit {
+		w.queue.Remove(e)
+		close(e.Value.(chan struct{}))
+		return
+	}
+	w.inflight--
+}
+
+// setLimit sets the effective size of the window, between 1 and the
+// configured size, handing the slots it frees over to queued callers.
+// Requests in flight above a reduced limit are not affected.
+func (w *window) setLimit(n uint) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size == 0 {
+		return
+	}
+	if n < 1 {
+		n = 1
+	}
+	if n > w.size {
+		n = w.size
+	}
+	w.limit = n
+	for w.inflight < w.limit && w.queue.Len() > 0 {
+		e := w.queue.Front()
+		w.queue.Remove(e)
+		close(e.Value.(chan struct{}))
+		w.inflight++
+	}
+}
+
+// status returns the current occupancy of the window.
+func (w *window) status() WindowStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WindowStatus{
+		Size:     int(w.size),
+		Inflight: int(w.inflight),
+		Queued:   w.queue.Len(),
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	w := newWindow(1, false)
+	ctx := context.Background()
+	if err := w.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.acquire(ctx); err != ErrMaxWindowSize {
+		t.Fatalf("unexpected error: want %v, have %v", ErrMaxWindowSize, err)
+	}
+	w.release()
+	if s := w.status(); s.Inflight != 0 {
+		t.Fatalf("unexpected inflight: want 0, have %d", s.Inflight)
+	}
+}
+
+func TestWindowWait(t *testing.T) {
+	w := newWindow(1, true)
+	ctx := context.Background()
+	if err := w.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// A cancelled caller leaves the queue.
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := w.acquire(cctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: want %v, have %v", context.DeadlineExceeded, err)
+	}
+	// Queued callers are served in order.
+	order := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func(i int) {
+			w.acquire(ctx)
+			order <- i
+		}(i)
+		for w.status().Queued != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	want := WindowStatus{Size: 1, Inflight: 1, Queued: 2}
+	if s := w.status(); s != want {
+		t.Fatalf("unexpected status: want %#v, have %#v", want, s)
+	}
+	for i := 0; i < 2; i++ {
+		w.release()
+		if n := <-order; n != i {
+			t.Fatalf("unexpected order: want %d, have %d", i, n)
+		}
+	}
+	w.release()
+	want = WindowStatus{Size: 1}
+	if s := w.status(); s != want {
+		t.Fatalf("unexpected status: want %#v, have %#v", want, s)
+	}
+}